@@ -0,0 +1,27 @@
+package clock
+
+import "time"
+
+// Clock tells the time. Scaling modes take a Clock rather than calling
+// time.Now so the caller decides what "now" is.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is a Clock backed by the system time.
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Offset is a Clock running By ahead of Clock, e.g. to ask a mode when it
+// will next change a while from now.
+type Offset struct {
+	Clock Clock
+	By    time.Duration
+}
+
+func (o Offset) Now() time.Time {
+	return o.Clock.Now().Add(o.By)
+}
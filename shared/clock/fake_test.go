@@ -0,0 +1,21 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+	if got := f.Now(); !got.Equal(start) {
+		t.Fatalf("Now() = %s, want %s", got, start)
+	}
+	if got := f.Advance(90 * time.Second); !got.Equal(start.Add(90 * time.Second)) {
+		t.Errorf("Advance() = %s, want %s", got, start.Add(90*time.Second))
+	}
+	f.Set(start)
+	if got := f.Now(); !got.Equal(start) {
+		t.Errorf("Now() after Set = %s, want %s", got, start)
+	}
+}
@@ -0,0 +1,41 @@
+package shared
+
+import "fmt"
+
+// The operator publishes its last Evaluation of a Ship to the captain in the
+// <ship>-evaluation ConfigMap, mounted into the captain at EvaluationDir. The
+// captain can't evaluate every mode itself, it has no access to the Secrets
+// they read.
+const (
+	EvaluationDir  = "/etc/freyr/evaluation"
+	EvaluationFile = "evaluation.json"
+)
+
+// SeriesDir holds the replay series the operator mounts into the captain from
+// the ConfigMaps they're read from, ReplaySeriesFile for the Ship and
+// ChildReplaySeriesFile for each composite child.
+const (
+	SeriesDir        = "/etc/freyr/series"
+	ReplaySeriesFile = "replay"
+)
+
+// ChildReplaySeriesFile names the replay series of composite child i.
+func ChildReplaySeriesFile(i int) string {
+	return fmt.Sprintf("composite-%d-replay", i)
+}
+
+// Evaluation is what the operator made of a Ship's mode on its last reconcile.
+type Evaluation struct {
+	// Target is the replica count applied to the conscripts, after the
+	// scaling policy.
+	Target        int32          `json:"target"`
+	Contributions []Contribution `json:"contributions,omitempty"`
+}
+
+// Contribution is one composite child's part in the target.
+type Contribution struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`
+	Target int32  `json:"target"`
+	Error  string `json:"error,omitempty"`
+}
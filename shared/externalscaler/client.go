@@ -0,0 +1,110 @@
+package externalscaler
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSOptions configure the connection to a scaler. The PEM encoded CA,
+// certificate and key are optional, the system roots are trusted without a CA
+// and no client certificate is presented without a certificate and key.
+type TLSOptions struct {
+	ServerName         string
+	InsecureSkipVerify bool
+	CA                 []byte
+	Cert               []byte
+	Key                []byte
+}
+
+// Config builds the tls.Config for the options.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if len(o.CA) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(o.CA) {
+			return nil, errors.New("no certificates found in CA")
+		}
+		cfg.RootCAs = pool
+	}
+	if len(o.Cert) > 0 || len(o.Key) > 0 {
+		cert, err := tls.X509KeyPair(o.Cert, o.Key)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// maxReconnectDelay caps the backoff of a client reconnecting to its scaler.
+const maxReconnectDelay = 10 * time.Second
+
+// conns holds a client per scaler address and TLS options, so evaluations
+// reuse one connection rather than dialling for every call. Clients left
+// behind when a Ship's certificates rotate go idle and drop their transport.
+var (
+	connsMu sync.Mutex
+	conns   = map[string]*grpc.ClientConn{}
+)
+
+// GetTarget asks the scaler at address for a target, over a plaintext
+// connection when opts is nil. The deadline of ctx bounds the whole call.
+func GetTarget(ctx context.Context, address string, opts *TLSOptions, req *GetTargetRequest) (*GetTargetResponse, error) {
+	conn, err := client(address, opts)
+	if err != nil {
+		return nil, err
+	}
+	return NewExternalScalerClient(conn).GetTarget(ctx, req)
+}
+
+func client(address string, opts *TLSOptions) (*grpc.ClientConn, error) {
+	key := address
+	if opts != nil {
+		h := sha256.New()
+		fmt.Fprintf(h, "%q %t %q %q %q", opts.ServerName, opts.InsecureSkipVerify, opts.CA, opts.Cert, opts.Key)
+		key += "|" + hex.EncodeToString(h.Sum(nil))
+	}
+
+	connsMu.Lock()
+	defer connsMu.Unlock()
+	if conn, ok := conns[key]; ok {
+		return conn, nil
+	}
+	creds := insecure.NewCredentials()
+	if opts != nil {
+		cfg, err := opts.Config()
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(cfg)
+	}
+	// Calls fail fast while a cached client waits to reconnect, so keep the
+	// wait short enough that a restarted scaler is back for the next reconcile.
+	reconnect := backoff.DefaultConfig
+	reconnect.MaxDelay = maxReconnectDelay
+	conn, err := grpc.NewClient(address,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithConnectParams(grpc.ConnectParams{Backoff: reconnect}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conns[key] = conn
+	return conn, nil
+}
@@ -0,0 +1,6 @@
+// Package externalscaler holds the gRPC protocol spoken between the operator
+// and out of process scalers used by external mode, along with a client for
+// it.
+package externalscaler
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative externalscaler.proto
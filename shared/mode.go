@@ -1,19 +1,220 @@
 package shared
 
 type OperatorSpec struct {
-	Mode    string      `json:"mode,omitempty"`
-	Weather WeatherMode `json:"weather,omitempty"`
-	Trig    TrigMode    `json:"trig,omitempty"`
+	Mode       string         `json:"mode,omitempty"`
+	Replicas   *int32         `json:"replicas,omitempty"`
+	Scaling    ScalingPolicy  `json:"scaling,omitempty"`
+	Weather    WeatherMode    `json:"weather,omitempty"`
+	Trig       TrigMode       `json:"trig,omitempty"`
+	Schedule   ScheduleMode   `json:"schedule,omitempty"`
+	Prometheus PrometheusMode `json:"prometheus,omitempty"`
+	External   ExternalMode   `json:"external,omitempty"`
+	Composite  CompositeMode  `json:"composite,omitempty"`
+	Replay     ReplayMode     `json:"replay,omitempty"`
+}
+
+// ScalingPolicy constrains the target produced by any mode before it is
+// applied to the conscripts.
+type ScalingPolicy struct {
+	MinReplicas      *int32       `json:"minReplicas,omitempty"`
+	MaxReplicas      *int32       `json:"maxReplicas,omitempty"`
+	FallbackReplicas *int32       `json:"fallbackReplicas,omitempty"`
+	LeadTime         string       `json:"leadTime,omitempty"`
+	ScaleUp          ScalingRules `json:"scaleUp,omitempty"`
+	ScaleDown        ScalingRules `json:"scaleDown,omitempty"`
+}
+
+// ScalingRules limit scaling in one direction, in the spirit of the
+// HorizontalPodAutoscaler behavior API.
+type ScalingRules struct {
+	MaxStep             *int32 `json:"maxStep,omitempty"`
+	StepPeriod          string `json:"stepPeriod,omitempty"`
+	StabilizationWindow string `json:"stabilizationWindow,omitempty"`
 }
 
 type WeatherMode struct {
-	Country string `json:"country,omitempty"`
-	City    string `json:"city,omitempty"`
-	APIKey  string `json:"apiKey,omitempty"`
+	Provider        string         `json:"provider,omitempty"`
+	URL             string         `json:"url,omitempty"`
+	GeocodingURL    string         `json:"geocodingURL,omitempty"`
+	Country         string         `json:"country,omitempty"`
+	City            string         `json:"city,omitempty"`
+	APIKey          string         `json:"apiKey,omitempty"`
+	APIKeySecretRef *SecretKeyRef  `json:"apiKeySecretRef,omitempty"`
+	PollInterval    string         `json:"pollInterval,omitempty"`
+	Signal          string         `json:"signal,omitempty"`
+	Mapping         WeatherMapping `json:"mapping,omitempty"`
+}
+
+// WeatherMapping turns the weather signal into a replica count. Input bounds
+// and thresholds are decimal strings so they survive the CRD, e.g. "-5.5".
+type WeatherMapping struct {
+	Type       string             `json:"type,omitempty"`
+	InputMin   string             `json:"inputMin,omitempty"`
+	InputMax   string             `json:"inputMax,omitempty"`
+	OutputMin  int32              `json:"outputMin,omitempty"`
+	OutputMax  int32              `json:"outputMax,omitempty"`
+	Thresholds []WeatherThreshold `json:"thresholds,omitempty"`
+}
+
+// WeatherThreshold targets Replicas once the signal reaches Above.
+type WeatherThreshold struct {
+	Above    string `json:"above"`
+	Replicas int32  `json:"replicas"`
+}
+
+// SecretKeyRef names a key within a Secret in the Ship's namespace. The
+// operator resolves it into the matching inline field before a mode runs.
+type SecretKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 type TrigMode struct {
 	Duration string `json:"duration,omitempty"`
 	Min      int32  `json:"min,omitempty"`
 	Max      int32  `json:"max,omitempty"`
+	Waveform string `json:"waveform,omitempty"`
+	// Phase is a duration the wave is shifted by, e.g. "75s".
+	Phase string `json:"phase,omitempty"`
+	// Amplitude is a decimal fraction of the min to max range, kept as a
+	// string to avoid floats in the CRD.
+	Amplitude string `json:"amplitude,omitempty"`
+	// DutyCycle is the percentage of the period square and pulse waves spend
+	// high.
+	DutyCycle int32 `json:"dutyCycle,omitempty"`
+	Seed      int64 `json:"seed,omitempty"`
+	// Anchor is a timestamp a period starts at, read in Timezone when it has
+	// no offset.
+	Anchor   string `json:"anchor,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ScheduleMode targets the replica count of the cron entry which fired most
+// recently.
+type ScheduleMode struct {
+	// Timezone is the IANA zone used by entries without their own, e.g.
+	// Australia/Melbourne. Defaults to UTC.
+	Timezone string          `json:"timezone,omitempty"`
+	Entries  []ScheduleEntry `json:"entries,omitempty"`
+}
+
+type ScheduleEntry struct {
+	Name     string `json:"name,omitempty"`
+	Cron     string `json:"cron"`
+	Replicas int32  `json:"replicas"`
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// PrometheusMode targets enough replicas for the result of a PromQL query to
+// stay at or below TargetPerReplica each.
+type PrometheusMode struct {
+	ServerURL string `json:"serverURL,omitempty"`
+	Query     string `json:"query,omitempty"`
+	// TargetPerReplica is a decimal, kept as a string to avoid floats in the
+	// CRD.
+	TargetPerReplica string `json:"targetPerReplica,omitempty"`
+	MinReplicas      int32  `json:"minReplicas,omitempty"`
+	MaxReplicas      int32  `json:"maxReplicas,omitempty"`
+	Interval         string `json:"interval,omitempty"`
+}
+
+// ExternalMode asks an out of process gRPC scaler for the target, see the
+// externalscaler package for the protocol.
+type ExternalMode struct {
+	Address  string            `json:"address,omitempty"`
+	Spec     map[string]string `json:"spec,omitempty"`
+	Timeout  string            `json:"timeout,omitempty"`
+	Interval string            `json:"interval,omitempty"`
+	TLS      *ExternalTLS      `json:"tls,omitempty"`
+}
+
+type ExternalTLS struct {
+	ServerName         string        `json:"serverName,omitempty"`
+	InsecureSkipVerify bool          `json:"insecureSkipVerify,omitempty"`
+	CASecretRef        *SecretKeyRef `json:"caSecretRef,omitempty"`
+	CertSecretRef      *SecretRef    `json:"certSecretRef,omitempty"`
+
+	// CA, Cert and Key are resolved from the Secret references by the
+	// operator and never serialized.
+	CA   []byte `json:"-"`
+	Cert []byte `json:"-"`
+	Key  []byte `json:"-"`
+}
+
+// ReplayMode plays back a recorded series of (offset, replicas) points read
+// from a ConfigMap, see the replay package.
+type ReplayMode struct {
+	SeriesConfigMapRef *ConfigMapKeyRef `json:"seriesConfigMapRef,omitempty"`
+	// Format is csv or json.
+	Format string `json:"format,omitempty"`
+	// End is loop or clamp.
+	End string `json:"end,omitempty"`
+	// Interpolation is linear or step.
+	Interpolation string `json:"interpolation,omitempty"`
+	// Anchor is the timestamp of offset 0, read in Timezone when it has no
+	// offset.
+	Anchor   string `json:"anchor,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+
+	// Series is resolved from SeriesConfigMapRef by the operator, and read
+	// from the mounted ConfigMap by the captain. It is never serialized.
+	Series string `json:"-"`
+}
+
+// ConfigMapKeyRef names a key within a ConfigMap in the Ship's namespace.
+type ConfigMapKeyRef struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+// SecretRef names a Secret in the Ship's namespace.
+type SecretRef struct {
+	Name string `json:"name"`
+}
+
+// CompositeMode combines the targets of several child modes.
+type CompositeMode struct {
+	// Aggregation is one of min, max, sum or avg.
+	Aggregation string           `json:"aggregation,omitempty"`
+	Children    []CompositeChild `json:"children,omitempty"`
+}
+
+// CompositeChild is a mode and its block of the spec. Composites can't be
+// nested.
+type CompositeChild struct {
+	Name       string         `json:"name,omitempty"`
+	Mode       string         `json:"mode"`
+	Replicas   *int32         `json:"replicas,omitempty"`
+	Weather    WeatherMode    `json:"weather,omitempty"`
+	Trig       TrigMode       `json:"trig,omitempty"`
+	Schedule   ScheduleMode   `json:"schedule,omitempty"`
+	Prometheus PrometheusMode `json:"prometheus,omitempty"`
+	External   ExternalMode   `json:"external,omitempty"`
+	Replay     ReplayMode     `json:"replay,omitempty"`
+}
+
+// Spec returns the operator spec the child's mode is evaluated against.
+func (c CompositeChild) Spec() OperatorSpec {
+	return OperatorSpec{
+		Mode:       c.Mode,
+		Replicas:   c.Replicas,
+		Weather:    c.Weather,
+		Trig:       c.Trig,
+		Schedule:   c.Schedule,
+		Prometheus: c.Prometheus,
+		External:   c.External,
+		Replay:     c.Replay,
+	}
+}
+
+// SetSpec copies the mode blocks of spec back onto the child, e.g. after they
+// were defaulted.
+func (c *CompositeChild) SetSpec(spec OperatorSpec) {
+	c.Replicas = spec.Replicas
+	c.Weather = spec.Weather
+	c.Trig = spec.Trig
+	c.Schedule = spec.Schedule
+	c.Prometheus = spec.Prometheus
+	c.External = spec.External
+	c.Replay = spec.Replay
 }
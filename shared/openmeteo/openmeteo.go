@@ -0,0 +1,303 @@
+// Package openmeteo reads the current weather and forecast from an Open-Meteo
+// compatible API. It needs no API key, and the forecast API can be
+// self-hosted.
+package openmeteo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/weather"
+)
+
+const (
+	DefaultBaseURL      = "https://api.open-meteo.com"
+	DefaultGeocodingURL = "https://geocoding-api.open-meteo.com"
+	// DefaultForecastTTL is how long a forecast is reused. Open-Meteo updates
+	// its models at most hourly.
+	DefaultForecastTTL = 15 * time.Minute
+	// defaultTimeout bounds requests when HTTPClient is unset.
+	defaultTimeout = 10 * time.Second
+)
+
+// ErrNoResults is returned when geocoding matches no location.
+var ErrNoResults = errors.New("openmeteo: no results")
+
+// currentVariables are requested from the forecast API, in the order of
+// weather.Conditions.
+const currentVariables = "temperature_2m,apparent_temperature,relative_humidity_2m,wind_speed_10m,cloud_cover"
+
+// Client calls the Open-Meteo forecast and geocoding APIs. Geocoded locations
+// are remembered for the life of the Client, and forecasts for ForecastTTL.
+// The zero value is ready to use and a Client is safe for concurrent use.
+type Client struct {
+	// BaseURL serves /v1/forecast, it defaults to DefaultBaseURL.
+	BaseURL string
+	// GeocodingURL serves /v1/search, it defaults to DefaultGeocodingURL.
+	GeocodingURL string
+	// HTTPClient defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+	// ForecastTTL defaults to DefaultForecastTTL. A negative TTL disables the
+	// cache.
+	ForecastTTL time.Duration
+	// Clock expires cached forecasts, it defaults to clock.Real.
+	Clock clock.Clock
+
+	geocodes sync.Map
+
+	forecastsMu sync.Mutex
+	forecasts   map[coordinates]cachedForecast
+}
+
+// cachedForecast is an hourly forecast days long, fetched before expires.
+type cachedForecast struct {
+	readings []weather.Reading
+	days     int
+	expires  time.Time
+}
+
+var (
+	_ weather.Provider   = &Client{}
+	_ weather.Forecaster = &Client{}
+)
+
+// maxForecastDays is as far ahead as the forecast API reaches.
+const maxForecastDays = 16
+
+type coordinates struct {
+	Lat float64
+	Lon float64
+}
+
+type searchResponse struct {
+	Results []struct {
+		Name        string  `json:"name"`
+		Latitude    float64 `json:"latitude"`
+		Longitude   float64 `json:"longitude"`
+		CountryCode string  `json:"country_code"`
+	} `json:"results"`
+}
+
+type forecastResponse struct {
+	Current struct {
+		Temperature         float64 `json:"temperature_2m"`
+		ApparentTemperature float64 `json:"apparent_temperature"`
+		RelativeHumidity    float64 `json:"relative_humidity_2m"`
+		WindSpeed           float64 `json:"wind_speed_10m"`
+		CloudCover          float64 `json:"cloud_cover"`
+	} `json:"current"`
+}
+
+type hourlyResponse struct {
+	Hourly struct {
+		Time                []int64   `json:"time"`
+		Temperature         []float64 `json:"temperature_2m"`
+		ApparentTemperature []float64 `json:"apparent_temperature"`
+		RelativeHumidity    []float64 `json:"relative_humidity_2m"`
+		WindSpeed           []float64 `json:"wind_speed_10m"`
+		CloudCover          []float64 `json:"cloud_cover"`
+	} `json:"hourly"`
+}
+
+// Conditions geocodes l and reads its current conditions.
+func (c *Client) Conditions(ctx context.Context, l weather.Location) (weather.Conditions, error) {
+	coords, err := c.geocode(ctx, l)
+	if err != nil {
+		return weather.Conditions{}, err
+	}
+
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", coords.Lat))
+	params.Set("longitude", fmt.Sprintf("%f", coords.Lon))
+	params.Set("current", currentVariables)
+	params.Set("wind_speed_unit", "ms")
+	var fr forecastResponse
+	err = c.get(ctx, or(c.BaseURL, DefaultBaseURL), []string{"v1", "forecast"}, params, &fr)
+	if err != nil {
+		return weather.Conditions{}, err
+	}
+	return weather.Conditions{
+		Temp:      fr.Current.Temperature,
+		FeelsLike: fr.Current.ApparentTemperature,
+		Humidity:  fr.Current.RelativeHumidity,
+		WindSpeed: fr.Current.WindSpeed,
+		Clouds:    fr.Current.CloudCover,
+	}, nil
+}
+
+// Forecast geocodes l and reads its hourly forecast. The API forecasts from
+// the start of today, so from is expected to be around now, and reaches at
+// most 16 days ahead.
+func (c *Client) Forecast(ctx context.Context, l weather.Location, from, to time.Time) ([]weather.Reading, error) {
+	coords, err := c.geocode(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	days := min(max(int(to.Sub(from).Hours()/24)+2, 1), maxForecastDays)
+	if readings, ok := c.cachedForecast(coords, days); ok {
+		return weather.Window(readings, from, to), nil
+	}
+
+	params := url.Values{}
+	params.Set("latitude", fmt.Sprintf("%f", coords.Lat))
+	params.Set("longitude", fmt.Sprintf("%f", coords.Lon))
+	params.Set("hourly", currentVariables)
+	params.Set("wind_speed_unit", "ms")
+	params.Set("timeformat", "unixtime")
+	params.Set("forecast_days", strconv.Itoa(days))
+	var hr hourlyResponse
+	err = c.get(ctx, or(c.BaseURL, DefaultBaseURL), []string{"v1", "forecast"}, params, &hr)
+	if err != nil {
+		return nil, err
+	}
+
+	h := hr.Hourly
+	readings := make([]weather.Reading, 0, len(h.Time))
+	for i, at := range h.Time {
+		readings = append(readings, weather.Reading{
+			At: time.Unix(at, 0).UTC(),
+			Conditions: weather.Conditions{
+				Temp:      valueAt(h.Temperature, i),
+				FeelsLike: valueAt(h.ApparentTemperature, i),
+				Humidity:  valueAt(h.RelativeHumidity, i),
+				WindSpeed: valueAt(h.WindSpeed, i),
+				Clouds:    valueAt(h.CloudCover, i),
+			},
+		})
+	}
+	c.cacheForecast(coords, days, readings)
+	return weather.Window(readings, from, to), nil
+}
+
+// cachedForecast returns the forecast at coords if one at least days long
+// hasn't expired.
+func (c *Client) cachedForecast(coords coordinates, days int) ([]weather.Reading, bool) {
+	c.forecastsMu.Lock()
+	defer c.forecastsMu.Unlock()
+	f, ok := c.forecasts[coords]
+	if !ok || f.days < days || !c.clock().Now().Before(f.expires) {
+		return nil, false
+	}
+	return f.readings, true
+}
+
+// cacheForecast remembers the forecast at coords for the TTL, sweeping the
+// expired ones.
+func (c *Client) cacheForecast(coords coordinates, days int, readings []weather.Reading) {
+	ttl := c.ForecastTTL
+	if ttl == 0 {
+		ttl = DefaultForecastTTL
+	}
+	if ttl < 0 {
+		return
+	}
+	c.forecastsMu.Lock()
+	defer c.forecastsMu.Unlock()
+	now := c.clock().Now()
+	if c.forecasts == nil {
+		c.forecasts = map[coordinates]cachedForecast{}
+	}
+	for k, f := range c.forecasts {
+		if !now.Before(f.expires) {
+			delete(c.forecasts, k)
+		}
+	}
+	c.forecasts[coords] = cachedForecast{readings: readings, days: days, expires: now.Add(ttl)}
+}
+
+func (c *Client) clock() clock.Clock {
+	if c.Clock == nil {
+		return clock.Real{}
+	}
+	return c.Clock
+}
+
+// valueAt guards against hourly series shorter than their times.
+func valueAt(values []float64, i int) float64 {
+	if i < len(values) {
+		return values[i]
+	}
+	return 0
+}
+
+// geocode finds the first search result for the city within the country.
+func (c *Client) geocode(ctx context.Context, l weather.Location) (coordinates, error) {
+	key := l.City + "," + l.Country
+	if v, ok := c.geocodes.Load(key); ok {
+		return v.(coordinates), nil
+	}
+
+	params := url.Values{}
+	params.Set("name", l.City)
+	params.Set("count", "10")
+	params.Set("format", "json")
+	if l.Country != "" {
+		params.Set("countryCode", l.Country)
+	}
+	var sr searchResponse
+	err := c.get(ctx, or(c.GeocodingURL, DefaultGeocodingURL), []string{"v1", "search"}, params, &sr)
+	if err != nil {
+		return coordinates{}, err
+	}
+	for _, r := range sr.Results {
+		if l.Country == "" || strings.EqualFold(r.CountryCode, l.Country) {
+			coords := coordinates{Lat: r.Latitude, Lon: r.Longitude}
+			c.geocodes.Store(key, coords)
+			return coords, nil
+		}
+	}
+	return coordinates{}, fmt.Errorf("%w for %s", ErrNoResults, key)
+}
+
+func (c *Client) get(ctx context.Context, base string, path []string, params url.Values, v any) error {
+	u, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("openmeteo: invalid url: %w", err)
+	}
+	u = u.JoinPath(path...)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("openmeteo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("openmeteo: HTTP %d: %s", resp.StatusCode, body.Reason)
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("openmeteo: decoding response: %w", err)
+	}
+	return nil
+}
+
+func or(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
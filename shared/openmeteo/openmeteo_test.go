@@ -0,0 +1,139 @@
+package openmeteo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/weather"
+)
+
+func TestClientConditions(t *testing.T) {
+	var searches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/search", func(w http.ResponseWriter, r *http.Request) {
+		searches.Add(1)
+		if r.URL.Query().Get("name") != "Melbourne" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		// Open-Meteo ranks Melbourne, Florida above Melbourne, Victoria for
+		// some queries, the country code must pick between them.
+		fmt.Fprint(w, `{"results":[
+			{"name":"Melbourne","latitude":28.08,"longitude":-80.61,"country_code":"US"},
+			{"name":"Melbourne","latitude":-37.81,"longitude":144.96,"country_code":"AU"}
+		]}`)
+	})
+	mux.HandleFunc("/v1/forecast", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("latitude") != "-37.810000" || q.Get("wind_speed_unit") != "ms" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":true,"reason":"unexpected query %s"}`, r.URL.RawQuery)
+			return
+		}
+		fmt.Fprint(w, `{"current":{"temperature_2m":14.2,"apparent_temperature":12.8,"relative_humidity_2m":81,"wind_speed_10m":6.3,"cloud_cover":90}}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, GeocodingURL: srv.URL}
+	melbourne := weather.Location{City: "Melbourne", Country: "AU"}
+	for range 2 {
+		got, err := c.Conditions(context.Background(), melbourne)
+		if err != nil {
+			t.Fatalf("Conditions() error = %v", err)
+		}
+		want := weather.Conditions{Temp: 14.2, FeelsLike: 12.8, Humidity: 81, WindSpeed: 6.3, Clouds: 90}
+		if got != want {
+			t.Errorf("Conditions() = %+v, want %+v", got, want)
+		}
+	}
+	if n := searches.Load(); n != 1 {
+		t.Errorf("geocoded %d times, want 1", n)
+	}
+
+	_, err := c.Conditions(context.Background(), weather.Location{City: "Atlantis", Country: "GR"})
+	if !errors.Is(err, ErrNoResults) {
+		t.Errorf("Conditions() error = %v, want %v", err, ErrNoResults)
+	}
+}
+
+func TestClientReportsReason(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":true,"reason":"Latitude must be in range of -90 to 90°."}`)
+	}))
+	defer srv.Close()
+
+	c := &Client{BaseURL: srv.URL, GeocodingURL: srv.URL}
+	_, err := c.Conditions(context.Background(), weather.Location{City: "Melbourne", Country: "AU"})
+	if err == nil || err.Error() != "openmeteo: HTTP 400: Latitude must be in range of -90 to 90°." {
+		t.Errorf("Conditions() error = %v", err)
+	}
+}
+
+func TestClientForecast(t *testing.T) {
+	var forecasts atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/search", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"results":[{"name":"Melbourne","latitude":-37.81,"longitude":144.96,"country_code":"AU"}]}`)
+	})
+	mux.HandleFunc("/v1/forecast", func(w http.ResponseWriter, r *http.Request) {
+		forecasts.Add(1)
+		q := r.URL.Query()
+		if q.Get("timeformat") != "unixtime" || q.Get("forecast_days") != "2" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":true,"reason":"unexpected query %s"}`, r.URL.RawQuery)
+			return
+		}
+		fmt.Fprint(w, `{"hourly":{
+			"time":[1746057600,1746061200,1746064800,1746068400],
+			"temperature_2m":[10,11,13,16],
+			"apparent_temperature":[8,9,12,15],
+			"relative_humidity_2m":[90,85,70,60],
+			"wind_speed_10m":[1,2,3,4],
+			"cloud_cover":[100,80,40,0]
+		}}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	from := time.Unix(1746057600, 0).Add(90 * time.Minute)
+	clk := clock.NewFake(from)
+	c := &Client{BaseURL: srv.URL, GeocodingURL: srv.URL, Clock: clk}
+	readings, err := c.Forecast(context.Background(), weather.Location{City: "Melbourne", Country: "AU"}, from, from.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	want := []weather.Reading{
+		{At: time.Unix(1746061200, 0).UTC(), Conditions: weather.Conditions{Temp: 11, FeelsLike: 9, Humidity: 85, WindSpeed: 2, Clouds: 80}},
+		{At: time.Unix(1746064800, 0).UTC(), Conditions: weather.Conditions{Temp: 13, FeelsLike: 12, Humidity: 70, WindSpeed: 3, Clouds: 40}},
+	}
+	if fmt.Sprint(readings) != fmt.Sprint(want) {
+		t.Errorf("Forecast() = %v, want %v", readings, want)
+	}
+
+	readings, err = c.Forecast(context.Background(), weather.Location{City: "Melbourne", Country: "AU"}, from, from.Add(20*time.Minute))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if fmt.Sprint(readings) != fmt.Sprint(want[:1]) {
+		t.Errorf("Forecast() from the cache = %v, want %v", readings, want[:1])
+	}
+	if n := forecasts.Load(); n != 1 {
+		t.Errorf("forecast read %d times within the TTL, want 1", n)
+	}
+	clk.Advance(DefaultForecastTTL)
+	if _, err := c.Forecast(context.Background(), weather.Location{City: "Melbourne", Country: "AU"}, from, from.Add(time.Hour)); err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if n := forecasts.Load(); n != 2 {
+		t.Errorf("forecast read %d times after the TTL, want 2", n)
+	}
+}
@@ -0,0 +1,297 @@
+package openweather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/weather"
+)
+
+const (
+	// DefaultBaseURL serves both the geocoding and the current weather API.
+	DefaultBaseURL = "https://api.openweathermap.org"
+	// DefaultGeocodeCacheSize is how many locations are remembered. Cities
+	// don't move, so entries only leave the cache to make room.
+	DefaultGeocodeCacheSize = 128
+	// DefaultWeatherTTL is how long current conditions are reused. OpenWeather
+	// refreshes them roughly every 10m.
+	DefaultWeatherTTL = time.Minute
+	// DefaultForecastTTL is how long a forecast is reused. It runs in 3h
+	// steps, so changes far less often than the current conditions.
+	DefaultForecastTTL = 15 * time.Minute
+	// defaultTimeout bounds requests when HTTPClient is unset.
+	defaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrUnauthorized is returned for a missing, invalid or not yet activated
+	// API key.
+	ErrUnauthorized = errors.New("openweather: unauthorized")
+	// ErrNotFound is returned when OpenWeather does not know the requested
+	// resource.
+	ErrNotFound = errors.New("openweather: not found")
+	// ErrRateLimited is returned once the API key exceeds its call quota.
+	ErrRateLimited = errors.New("openweather: rate limited")
+	// ErrNoResults is returned when geocoding matches no location.
+	ErrNoResults = errors.New("openweather: no results")
+)
+
+// APIError is a non-200 response. It matches ErrUnauthorized, ErrNotFound and
+// ErrRateLimited with errors.Is according to its status code.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("openweather: HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("openweather: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// DefaultClient is used by the package level functions and weather mode.
+// Replace it, or change its fields, before it is first used.
+var DefaultClient = &Client{}
+
+// Client calls the OpenWeather API, caching geocoded locations and the current
+// conditions at each location. The zero value is ready to use and a Client is
+// safe for concurrent use.
+type Client struct {
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+	// HTTPClient defaults to a client with a 10s timeout.
+	HTTPClient *http.Client
+	// GeocodeCacheSize defaults to DefaultGeocodeCacheSize.
+	GeocodeCacheSize int
+	// WeatherTTL defaults to DefaultWeatherTTL. A negative TTL disables the
+	// cache.
+	WeatherTTL time.Duration
+	// ForecastTTL defaults to DefaultForecastTTL. A negative TTL disables the
+	// cache.
+	ForecastTTL time.Duration
+	// Clock expires cached conditions and forecasts, it defaults to
+	// clock.Real.
+	Clock clock.Clock
+
+	once      sync.Once
+	hc        *http.Client
+	geocodes  *lru[GeocodeResponse]
+	current   *ttlCache[Conditions]
+	forecasts *ttlCache[[]weather.Reading]
+}
+
+func (c *Client) init() {
+	c.once.Do(func() {
+		c.hc = c.HTTPClient
+		if c.hc == nil {
+			c.hc = &http.Client{Timeout: defaultTimeout}
+		}
+		size := c.GeocodeCacheSize
+		if size <= 0 {
+			size = DefaultGeocodeCacheSize
+		}
+		c.geocodes = newLRU[GeocodeResponse](size)
+		ttl := c.WeatherTTL
+		if ttl == 0 {
+			ttl = DefaultWeatherTTL
+		}
+		clk := c.Clock
+		if clk == nil {
+			clk = clock.Real{}
+		}
+		c.current = newTTLCache[Conditions](ttl, clk)
+		forecastTTL := c.ForecastTTL
+		if forecastTTL == 0 {
+			forecastTTL = DefaultForecastTTL
+		}
+		c.forecasts = newTTLCache[[]weather.Reading](forecastTTL, clk)
+	})
+}
+
+// Provider adapts a Client to weather.Provider for one API key.
+type Provider struct {
+	Client *Client
+	APIKey string
+}
+
+var (
+	_ weather.Provider   = Provider{}
+	_ weather.Forecaster = Provider{}
+)
+
+func (p Provider) Conditions(ctx context.Context, l Location) (Conditions, error) {
+	return p.Client.Conditions(ctx, p.APIKey, l)
+}
+
+// Forecast geocodes l and reads its forecast, which runs 5 days ahead in 3h
+// steps.
+func (p Provider) Forecast(ctx context.Context, l Location, from, to time.Time) ([]weather.Reading, error) {
+	g, err := p.Client.Geocode(ctx, p.APIKey, l)
+	if err != nil {
+		return nil, err
+	}
+	readings, err := p.Client.Forecast(ctx, p.APIKey, g.Lat, g.Lon)
+	if err != nil {
+		return nil, err
+	}
+	return weather.Window(readings, from, to), nil
+}
+
+// Geocode finds the coordinates of the first location matching l.
+func (c *Client) Geocode(ctx context.Context, apiKey string, l Location) (GeocodeResponse, error) {
+	c.init()
+	key := apiKey + "|" + l.City + "," + l.Country
+	if g, ok := c.geocodes.get(key); ok {
+		return g, nil
+	}
+
+	params := url.Values{}
+	params.Set("q", l.City+","+l.Country)
+	params.Set("limit", "1")
+	params.Set("appid", apiKey)
+	var results []GeocodeResponse
+	err := c.get(ctx, []string{"geo", "1.0", "direct"}, params, &results)
+	if err != nil {
+		return GeocodeResponse{}, err
+	}
+	if len(results) == 0 {
+		return GeocodeResponse{}, fmt.Errorf("%w for %s,%s", ErrNoResults, l.City, l.Country)
+	}
+	c.geocodes.add(key, results[0])
+	return results[0], nil
+}
+
+// Current reads the current conditions at a location in metric units.
+func (c *Client) Current(ctx context.Context, apiKey string, lat, lon float64) (Conditions, error) {
+	c.init()
+	key := fmt.Sprintf("%s|%.4f,%.4f", apiKey, lat, lon)
+	if cond, ok := c.current.get(key); ok {
+		return cond, nil
+	}
+
+	params := url.Values{}
+	params.Set("lat", fmt.Sprintf("%f", lat))
+	params.Set("lon", fmt.Sprintf("%f", lon))
+	params.Set("units", "metric")
+	params.Set("appid", apiKey)
+	var current currentResponse
+	err := c.get(ctx, []string{"data", "2.5", "weather"}, params, &current)
+	if err != nil {
+		return Conditions{}, err
+	}
+	cond := Conditions{
+		Temp:      current.Main.Temp,
+		FeelsLike: current.Main.FeelsLike,
+		Humidity:  float64(current.Main.Humidity),
+		WindSpeed: current.Wind.Speed,
+		Clouds:    float64(current.Clouds.All),
+	}
+	c.current.add(key, cond)
+	return cond, nil
+}
+
+// Forecast reads the 5 day forecast at a location in metric units, as
+// readings 3h apart. The forecast is cached for ForecastTTL.
+func (c *Client) Forecast(ctx context.Context, apiKey string, lat, lon float64) ([]weather.Reading, error) {
+	c.init()
+	key := fmt.Sprintf("%s|%.4f,%.4f", apiKey, lat, lon)
+	if readings, ok := c.forecasts.get(key); ok {
+		return readings, nil
+	}
+
+	params := url.Values{}
+	params.Set("lat", fmt.Sprintf("%f", lat))
+	params.Set("lon", fmt.Sprintf("%f", lon))
+	params.Set("units", "metric")
+	params.Set("appid", apiKey)
+	var forecast forecastResponse
+	err := c.get(ctx, []string{"data", "2.5", "forecast"}, params, &forecast)
+	if err != nil {
+		return nil, err
+	}
+	readings := make([]weather.Reading, 0, len(forecast.List))
+	for _, f := range forecast.List {
+		readings = append(readings, weather.Reading{
+			At: time.Unix(f.Dt, 0).UTC(),
+			Conditions: Conditions{
+				Temp:      f.Main.Temp,
+				FeelsLike: f.Main.FeelsLike,
+				Humidity:  float64(f.Main.Humidity),
+				WindSpeed: f.Wind.Speed,
+				Clouds:    float64(f.Clouds.All),
+			},
+		})
+	}
+	c.forecasts.add(key, readings)
+	return readings, nil
+}
+
+// Conditions geocodes l and reads its current conditions.
+func (c *Client) Conditions(ctx context.Context, apiKey string, l Location) (Conditions, error) {
+	g, err := c.Geocode(ctx, apiKey, l)
+	if err != nil {
+		return Conditions{}, err
+	}
+	return c.Current(ctx, apiKey, g.Lat, g.Lon)
+}
+
+func (c *Client) get(ctx context.Context, path []string, params url.Values, v any) error {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return fmt.Errorf("openweather: invalid base url: %w", err)
+	}
+	u = u.JoinPath(path...)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		// The request URL carries the API key, keep it out of the error.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("openweather: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return &APIError{StatusCode: resp.StatusCode, Message: body.Message}
+	}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("openweather: decoding response: %w", err)
+	}
+	return nil
+}
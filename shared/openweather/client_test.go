@@ -0,0 +1,231 @@
+package openweather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type stepClock struct {
+	now time.Time
+}
+
+func (c *stepClock) Now() time.Time {
+	return c.now
+}
+
+// fakeServer serves Melbourne and Sydney, counting requests per path.
+type fakeServer struct {
+	*httptest.Server
+	geocodes  atomic.Int32
+	current   atomic.Int32
+	forecasts atomic.Int32
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	f := &fakeServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/geo/1.0/direct", func(w http.ResponseWriter, r *http.Request) {
+		f.geocodes.Add(1)
+		switch r.URL.Query().Get("q") {
+		case "Melbourne,AU":
+			fmt.Fprint(w, `[{"name":"Melbourne","lat":-37.81,"lon":144.96,"country":"AU"}]`)
+		case "Sydney,AU":
+			fmt.Fprint(w, `[{"name":"Sydney","lat":-33.86,"lon":151.2,"country":"AU"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	mux.HandleFunc("/data/2.5/weather", func(w http.ResponseWriter, r *http.Request) {
+		f.current.Add(1)
+		if r.URL.Query().Get("units") != "metric" {
+			t.Errorf("expected metric units, got %q", r.URL.Query().Get("units"))
+		}
+		fmt.Fprint(w, `{"main":{"temp":18.4,"feels_like":16.9,"humidity":71},"wind":{"speed":5.1},"clouds":{"all":40}}`)
+	})
+	mux.HandleFunc("/data/2.5/forecast", func(w http.ResponseWriter, r *http.Request) {
+		f.forecasts.Add(1)
+		fmt.Fprint(w, `{"list":[
+			{"dt":1746057600,"main":{"temp":12.5,"feels_like":11,"humidity":80},"wind":{"speed":2},"clouds":{"all":90}},
+			{"dt":1746068400,"main":{"temp":15},"wind":{"speed":3.5},"clouds":{"all":20}},
+			{"dt":1746079200,"main":{"temp":19},"wind":{"speed":6},"clouds":{"all":0}}
+		]}`)
+	})
+	f.Server = httptest.NewServer(withKey(mux))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// withKey answers like OpenWeather does for the keys the tests reserve.
+func withKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("appid") {
+		case "invalid":
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"cod":401,"message":"Invalid API key."}`)
+		case "exhausted":
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"cod":429,"message":"Your account is temporary blocked."}`)
+		case "missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"cod":"404","message":"Internal error"}`)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func TestClientConditions(t *testing.T) {
+	f := newFakeServer(t)
+	c := &Client{BaseURL: f.URL}
+
+	got, err := c.Conditions(context.Background(), "key", Location{City: "Melbourne", Country: "AU"})
+	if err != nil {
+		t.Fatalf("Conditions() error = %v", err)
+	}
+	want := Conditions{Temp: 18.4, FeelsLike: 16.9, Humidity: 71, WindSpeed: 5.1, Clouds: 40}
+	if got != want {
+		t.Errorf("Conditions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestClientCachesGeocodesAndConditions(t *testing.T) {
+	f := newFakeServer(t)
+	clk := &stepClock{now: time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)}
+	c := &Client{BaseURL: f.URL, WeatherTTL: time.Minute, Clock: clk}
+	melbourne := Location{City: "Melbourne", Country: "AU"}
+
+	for range 3 {
+		if _, err := c.Conditions(context.Background(), "key", melbourne); err != nil {
+			t.Fatalf("Conditions() error = %v", err)
+		}
+	}
+	if n := f.geocodes.Load(); n != 1 {
+		t.Errorf("geocoded %d times, want 1", n)
+	}
+	if n := f.current.Load(); n != 1 {
+		t.Errorf("read the current weather %d times, want 1", n)
+	}
+
+	clk.now = clk.now.Add(time.Minute)
+	if _, err := c.Conditions(context.Background(), "key", melbourne); err != nil {
+		t.Fatalf("Conditions() error = %v", err)
+	}
+	if n := f.geocodes.Load(); n != 1 {
+		t.Errorf("geocoded %d times after the TTL, want 1", n)
+	}
+	if n := f.current.Load(); n != 2 {
+		t.Errorf("read the current weather %d times after the TTL, want 2", n)
+	}
+}
+
+func TestClientGeocodeEviction(t *testing.T) {
+	f := newFakeServer(t)
+	c := &Client{BaseURL: f.URL, GeocodeCacheSize: 1}
+	ctx := context.Background()
+	melbourne := Location{City: "Melbourne", Country: "AU"}
+	sydney := Location{City: "Sydney", Country: "AU"}
+
+	for _, l := range []Location{melbourne, melbourne, sydney, melbourne} {
+		if _, err := c.Geocode(ctx, "key", l); err != nil {
+			t.Fatalf("Geocode(%s) error = %v", l.City, err)
+		}
+	}
+	if n := f.geocodes.Load(); n != 3 {
+		t.Errorf("geocoded %d times, want 3", n)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	f := newFakeServer(t)
+	c := &Client{BaseURL: f.URL}
+	melbourne := Location{City: "Melbourne", Country: "AU"}
+
+	cases := []struct {
+		name string
+		key  string
+		loc  Location
+		want error
+	}{
+		{"unauthorized", "invalid", melbourne, ErrUnauthorized},
+		{"rate limited", "exhausted", melbourne, ErrRateLimited},
+		{"not found", "missing", melbourne, ErrNotFound},
+		{"no results", "key", Location{City: "Atlantis", Country: "GR"}, ErrNoResults},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.Conditions(context.Background(), tc.key, tc.loc)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Conditions() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	_, err := c.Conditions(context.Background(), "invalid", melbourne)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Invalid API key." {
+		t.Errorf("Conditions() error = %#v, want an APIError carrying the response message", err)
+	}
+}
+
+func TestClientHonoursContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c := &Client{BaseURL: srv.URL}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.Geocode(ctx, "secret-key", Location{City: "Melbourne", Country: "AU"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Geocode() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if strings.Contains(err.Error(), "secret-key") {
+		t.Errorf("Geocode() error leaks the API key: %v", err)
+	}
+}
+
+func TestProviderForecast(t *testing.T) {
+	f := newFakeServer(t)
+	from := time.Unix(1746057600, 0).Add(time.Hour)
+	clk := &stepClock{now: from}
+	p := Provider{Client: &Client{BaseURL: f.URL, Clock: clk}, APIKey: "key"}
+
+	readings, err := p.Forecast(context.Background(), Location{City: "Melbourne", Country: "AU"}, from, from.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if len(readings) != 2 {
+		t.Fatalf("Forecast() returned %d readings, want the 2 covering the window", len(readings))
+	}
+	want := Conditions{Temp: 12.5, FeelsLike: 11, Humidity: 80, WindSpeed: 2, Clouds: 90}
+	if readings[0].Conditions != want || !readings[0].At.Equal(time.Unix(1746057600, 0)) {
+		t.Errorf("first reading = %+v, want %+v at 00:00", readings[0], want)
+	}
+
+	// The forecast outlives the current conditions in the cache.
+	clk.now = clk.now.Add(DefaultWeatherTTL)
+	_, err = p.Forecast(context.Background(), Location{City: "Melbourne", Country: "AU"}, from, from.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.forecasts.Load(); got != 1 {
+		t.Errorf("made %d forecast requests, want 1 with the cache", got)
+	}
+
+	clk.now = from.Add(DefaultForecastTTL)
+	_, err = p.Forecast(context.Background(), Location{City: "Melbourne", Country: "AU"}, from, from.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.forecasts.Load(); got != 2 {
+		t.Errorf("made %d forecast requests after the TTL, want 2", got)
+	}
+}
@@ -1,15 +1,14 @@
+// Package openweather reads the current weather and forecast from the
+// OpenWeather API.
 package openweather
 
 import (
-	"encoding/json"
-	"fmt"
-	"net/http"
+	"context"
+
+	"github.com/socialviolation/freyr/shared/weather"
 )
 
-type Location struct {
-	Country string `json:"country"`
-	City    string `json:"city"`
-}
+type Location = weather.Location
 
 type LatLonTemp struct {
 	Lat  float64 `json:"lat"`
@@ -68,23 +67,31 @@ type currentResponse struct {
 	Cod      int    `json:"cod"`
 }
 
-func GetLatLon(apikey string, l Location) (LatLonTemp, error) {
-	url := fmt.Sprintf("http://api.openweathermap.org/geo/1.0/direct?q=%s,%s&limit=5&appid=%s", l.City, l.Country, apikey)
-	resp, err := http.Get(url)
-	if err != nil {
-		return LatLonTemp{}, err
-	}
-	defer resp.Body.Close()
+// forecastResponse is the part of the 5 day forecast weather mode reads.
+type forecastResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp      float64 `json:"temp"`
+			FeelsLike float64 `json:"feels_like"`
+			Humidity  int     `json:"humidity"`
+		} `json:"main"`
+		Wind struct {
+			Speed float64 `json:"speed"`
+		} `json:"wind"`
+		Clouds struct {
+			All int `json:"all"`
+		} `json:"clouds"`
+	} `json:"list"`
+}
 
-	var geocodeResponse []GeocodeResponse
-	err = json.NewDecoder(resp.Body).Decode(&geocodeResponse)
+// GetLatLon geocodes l with DefaultClient.
+func GetLatLon(apikey string, l Location) (LatLonTemp, error) {
+	g, err := DefaultClient.Geocode(context.Background(), apikey, l)
 	if err != nil {
 		return LatLonTemp{}, err
 	}
-	return LatLonTemp{
-		Lat: geocodeResponse[0].Lat,
-		Lon: geocodeResponse[0].Lon,
-	}, nil
+	return LatLonTemp{Lat: g.Lat, Lon: g.Lon}, nil
 }
 
 func GetTempByCountry(apikey string, l Location) (LatLonTemp, error) {
@@ -96,18 +103,20 @@ func GetTempByCountry(apikey string, l Location) (LatLonTemp, error) {
 }
 
 func GetTemp(apikey string, c LatLonTemp) (LatLonTemp, error) {
-	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/weather?lat=%f&lon=%f&exclude=hourly,daily&appid=%s&units=metric", c.Lat, c.Lon, apikey)
-	resp, err := http.Get(url)
+	conditions, err := DefaultClient.Current(context.Background(), apikey, c.Lat, c.Lon)
 	if err != nil {
 		return LatLonTemp{}, err
 	}
-
-	defer resp.Body.Close()
-	var current currentResponse
-	err = json.NewDecoder(resp.Body).Decode(&current)
-	if err != nil {
-		return LatLonTemp{}, err
-	}
-	c.Temp = int32(current.Main.Temp)
+	c.Temp = int32(conditions.Temp)
 	return c, nil
 }
+
+type Conditions = weather.Conditions
+
+func GetConditionsByCountry(apikey string, l Location) (Conditions, error) {
+	return DefaultClient.Conditions(context.Background(), apikey, l)
+}
+
+func GetConditions(apikey string, c LatLonTemp) (Conditions, error) {
+	return DefaultClient.Current(context.Background(), apikey, c.Lat, c.Lon)
+}
@@ -0,0 +1,115 @@
+// Package prometheus evaluates instant PromQL queries against the Prometheus
+// HTTP API.
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoSamples is returned when a query matches no series.
+var ErrNoSamples = errors.New("query returned no samples")
+
+type Client struct {
+	// BaseURL is the Prometheus server, e.g. http://prometheus:9090.
+	BaseURL string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type vectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  sampleValue       `json:"value"`
+}
+
+// sampleValue is the [timestamp, "value"] pair Prometheus encodes samples as.
+type sampleValue [2]any
+
+// Query evaluates an instant query at the given time. The query must produce
+// a scalar or a vector with exactly one sample, aggregate with sum() or
+// similar if it matches several series.
+func (c Client) Query(ctx context.Context, query string, at time.Time) (float64, error) {
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid server url: %w", err)
+	}
+	u = u.JoinPath("api", "v1", "query")
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("time", strconv.FormatFloat(float64(at.UnixMilli())/1000, 'f', 3, 64))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(params.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var qr queryResponse
+	err = json.NewDecoder(resp.Body).Decode(&qr)
+	if err != nil {
+		return 0, fmt.Errorf("decoding response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if qr.Status != "success" {
+		return 0, fmt.Errorf("query failed (HTTP %d): %s: %s", resp.StatusCode, qr.ErrorType, qr.Error)
+	}
+
+	switch qr.Data.ResultType {
+	case "scalar":
+		var v sampleValue
+		err = json.Unmarshal(qr.Data.Result, &v)
+		if err != nil {
+			return 0, err
+		}
+		return v.float()
+	case "vector":
+		var samples []vectorSample
+		err = json.Unmarshal(qr.Data.Result, &samples)
+		if err != nil {
+			return 0, err
+		}
+		if len(samples) == 0 {
+			return 0, ErrNoSamples
+		}
+		if len(samples) > 1 {
+			return 0, fmt.Errorf("query returned %d series, expected 1", len(samples))
+		}
+		return samples[0].Value.float()
+	default:
+		return 0, fmt.Errorf("unsupported result type %q, expected scalar or vector", qr.Data.ResultType)
+	}
+}
+
+func (v sampleValue) float() (float64, error) {
+	s, ok := v[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("malformed sample value %v", v[1])
+	}
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,85 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func stubServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.FormValue("query") != "sum(rate(http_requests_total[5m]))" {
+			t.Errorf("unexpected query %q", r.FormValue("query"))
+		}
+		if r.FormValue("time") != "1700000000.000" {
+			t.Errorf("unexpected time %q", r.FormValue("time"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    float64
+		wantErr bool
+	}{
+		{
+			name: "vector",
+			body: `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"42.5"]}]}}`,
+			want: 42.5,
+		},
+		{
+			name: "scalar",
+			body: `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"7"]}}`,
+			want: 7,
+		},
+		{
+			name:    "several series",
+			body:    `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"a":"1"},"value":[1,"1"]},{"metric":{"a":"2"},"value":[1,"2"]}]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "matrix",
+			body:    `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+			wantErr: true,
+		},
+		{
+			name:    "error",
+			body:    `{"status":"error","errorType":"bad_data","error":"parse error"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := stubServer(t, tt.body)
+			got, err := Client{BaseURL: srv.URL}.Query(context.Background(), "sum(rate(http_requests_total[5m]))", time.Unix(1700000000, 0))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Query() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Query() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryNoSamples(t *testing.T) {
+	srv := stubServer(t, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+	_, err := Client{BaseURL: srv.URL}.Query(context.Background(), "sum(rate(http_requests_total[5m]))", time.Unix(1700000000, 0))
+	if !errors.Is(err, ErrNoSamples) {
+		t.Fatalf("Query() error = %v, want ErrNoSamples", err)
+	}
+}
@@ -0,0 +1,316 @@
+// Package replay plays back a recorded series of (offset, replicas) points,
+// e.g. a day of production traffic captured as conscript counts. Offsets are
+// counted from an anchor, and the series either loops or holds its last point
+// once it runs out.
+package replay
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+// Series formats.
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// What happens after the last point.
+const (
+	// EndLoop starts the series again, the last offset is the length of a loop.
+	EndLoop = "loop"
+	// EndClamp holds the last point.
+	EndClamp = "clamp"
+)
+
+// How the replica count moves between points.
+const (
+	InterpolationLinear = "linear"
+	InterpolationStep   = "step"
+)
+
+// ErrNoSeries is returned by New when the spec carries no series, e.g. when
+// validating the Ship before the series is read from the ConfigMap.
+var ErrNoSeries = errors.New("series: no points")
+
+// Point is the replica count at Offset into the series.
+type Point struct {
+	Offset   time.Duration `json:"offset"`
+	Replicas int32         `json:"replicas"`
+}
+
+type Replay struct {
+	points        []Point
+	end           string
+	interpolation string
+	loc           *time.Location
+	// anchor is the wall clock time of offset 0 in loc.
+	anchor time.Time
+}
+
+// anchorLayouts are tried in order. Layouts without an offset are read in the
+// replay timezone.
+var anchorLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// New parses the options and the series of the spec. The format defaults to
+// json when the ConfigMap key ends in .json and csv otherwise, the end to loop
+// and the interpolation to linear. Offsets count from midnight on 1 January
+// 1970 in the timezone, UTC when empty, unless anchored elsewhere.
+func New(spec shared.ReplayMode) (*Replay, error) {
+	r := &Replay{end: spec.End, interpolation: spec.Interpolation}
+	switch r.end {
+	case "":
+		r.end = EndLoop
+	case EndLoop, EndClamp:
+	default:
+		return nil, fmt.Errorf("end: must be %s or %s, got %q", EndLoop, EndClamp, r.end)
+	}
+	switch r.interpolation {
+	case "":
+		r.interpolation = InterpolationLinear
+	case InterpolationLinear, InterpolationStep:
+	default:
+		return nil, fmt.Errorf("interpolation: must be %s or %s, got %q", InterpolationLinear, InterpolationStep, r.interpolation)
+	}
+	format := spec.Format
+	if format == "" {
+		format = FormatCSV
+		if ref := spec.SeriesConfigMapRef; ref != nil && strings.HasSuffix(ref.Key, ".json") {
+			format = FormatJSON
+		}
+	}
+	if format != FormatCSV && format != FormatJSON {
+		return nil, fmt.Errorf("format: must be %s or %s, got %q", FormatCSV, FormatJSON, format)
+	}
+
+	loc, err := time.LoadLocation(spec.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone: %w", err)
+	}
+	r.loc = loc
+	r.anchor = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	if spec.Anchor != "" {
+		anchor, err := parseAnchor(spec.Anchor, loc)
+		if err != nil {
+			return nil, fmt.Errorf("anchor: %w", err)
+		}
+		r.anchor = wallClock(anchor, loc)
+	}
+
+	if strings.TrimSpace(spec.Series) == "" {
+		return nil, ErrNoSeries
+	}
+	r.points, err = Parse([]byte(spec.Series), format)
+	if err != nil {
+		return nil, fmt.Errorf("series: %w", err)
+	}
+	if r.end == EndLoop && r.Length() == 0 {
+		return nil, errors.New("series: a looping series needs a point after offset 0")
+	}
+	return r, nil
+}
+
+// Parse reads a series of points in format. CSV has an offset and replica
+// count per row, with an optional header and # comments. JSON is an array of
+// {"offset", "replicas"} objects. Offsets are durations such as 90s, or a
+// number of seconds, and must increase from 0 or later.
+func Parse(data []byte, format string) ([]Point, error) {
+	var points []Point
+	var err error
+	switch format {
+	case FormatCSV:
+		points, err = parseCSV(data)
+	case FormatJSON:
+		points, err = parseJSON(data)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, errors.New("no points")
+	}
+	for i, p := range points {
+		if p.Replicas < 0 {
+			return nil, fmt.Errorf("point %d: replicas must not be negative, got %d", i, p.Replicas)
+		}
+		if p.Offset < 0 {
+			return nil, fmt.Errorf("point %d: offset must not be negative, got %s", i, p.Offset)
+		}
+		if i > 0 && p.Offset <= points[i-1].Offset {
+			return nil, fmt.Errorf("point %d: offset %s must be after %s", i, p.Offset, points[i-1].Offset)
+		}
+	}
+	return points, nil
+}
+
+func parseCSV(data []byte) ([]Point, error) {
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	var points []Point
+	for row := 0; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return points, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		offset, offsetErr := parseOffset(strings.TrimSpace(rec[0]))
+		replicas, replicasErr := strconv.ParseInt(strings.TrimSpace(rec[1]), 10, 32)
+		if offsetErr != nil || replicasErr != nil {
+			if row == 0 && offsetErr != nil && replicasErr != nil {
+				// A header.
+				continue
+			}
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, errors.Join(offsetErr, replicasErr))
+		}
+		points = append(points, Point{Offset: offset, Replicas: int32(replicas)})
+	}
+}
+
+func parseJSON(data []byte) ([]Point, error) {
+	var raw []struct {
+		Offset   json.RawMessage `json:"offset"`
+		Replicas int32           `json:"replicas"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	points := make([]Point, len(raw))
+	for i, r := range raw {
+		s := string(r.Offset)
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			s = unquoted
+		}
+		offset, err := parseOffset(s)
+		if err != nil {
+			return nil, fmt.Errorf("point %d: %w", i, err)
+		}
+		points[i] = Point{Offset: offset, Replicas: r.Replicas}
+	}
+	return points, nil
+}
+
+// parseOffset reads a duration, or a number of seconds.
+func parseOffset(s string) (time.Duration, error) {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		if math.IsNaN(secs) || math.IsInf(secs, 0) {
+			return 0, fmt.Errorf("offset: %q is not a finite number of seconds", s)
+		}
+		if math.Abs(secs) > math.MaxInt64/float64(time.Second) {
+			return 0, fmt.Errorf("offset: %q is beyond %s", s, time.Duration(math.MaxInt64))
+		}
+		return time.Duration(secs * float64(time.Second)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("offset: %q is neither a duration nor a number of seconds", s)
+	}
+	return d, nil
+}
+
+func parseAnchor(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range anchorLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a timestamp such as 2025-01-06T09:00:00", s)
+}
+
+// Points returns the parsed series.
+func (r *Replay) Points() []Point {
+	return r.points
+}
+
+// Length is the offset of the last point, the length of one loop.
+func (r *Replay) Length() time.Duration {
+	return r.points[len(r.points)-1].Offset
+}
+
+// Value is the replica count at t. Before the first point it holds the first.
+func (r *Replay) Value(t time.Time) float64 {
+	offset := r.offset(t)
+	i := r.segment(offset)
+	if i < 0 {
+		return float64(r.points[0].Replicas)
+	}
+	if i == len(r.points)-1 || r.interpolation == InterpolationStep {
+		return float64(r.points[i].Replicas)
+	}
+	from, to := r.points[i], r.points[i+1]
+	proportion := float64(offset-from.Offset) / float64(to.Offset-from.Offset)
+	return float64(from.Replicas) + proportion*float64(to.Replicas-from.Replicas)
+}
+
+// Target is Value rounded to a whole replica count.
+func (r *Replay) Target(t time.Time) int32 {
+	return int32(math.Round(r.Value(t)))
+}
+
+// Step is how long after t the target may next change: the next point for a
+// step series, or the shortest time the line to it takes to move by one
+// replica. Zero means it never changes, once a clamped series has ended.
+func (r *Replay) Step(t time.Time) time.Duration {
+	offset := r.offset(t)
+	i := r.segment(offset)
+	if i < 0 {
+		return r.points[0].Offset - offset
+	}
+	if i == len(r.points)-1 {
+		return 0
+	}
+	from, to := r.points[i], r.points[i+1]
+	untilNext := to.Offset - offset
+	delta := to.Replicas - from.Replicas
+	if r.interpolation == InterpolationStep || delta == 0 {
+		return untilNext
+	}
+	if delta < 0 {
+		delta = -delta
+	}
+	return min(untilNext, (to.Offset-from.Offset)/time.Duration(delta))
+}
+
+// offset is how far t is into the series, by the wall clock of the replay's
+// location. A looping series wraps into its first loop.
+func (r *Replay) offset(t time.Time) time.Duration {
+	offset := wallClock(t, r.loc).Sub(r.anchor)
+	if r.end == EndLoop {
+		offset %= r.Length()
+		if offset < 0 {
+			offset += r.Length()
+		}
+	}
+	return offset
+}
+
+// segment is the index of the last point at or before offset, or -1 before
+// the first point.
+func (r *Replay) segment(offset time.Duration) int {
+	return sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].Offset > offset
+	}) - 1
+}
+
+// wallClock is the local time at t in loc, as though loc were UTC.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
+}
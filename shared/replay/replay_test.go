@@ -0,0 +1,125 @@
+package replay
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+// epoch is offset 0 of a series with the default anchor.
+var epoch = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	want := []Point{{0, 2}, {90 * time.Second, 6}, {5 * time.Minute, 2}}
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"csv with a header and comments", FormatCSV, "offset,replicas\n# the morning peak\n0,2\n90s, 6\n5m,2\n"},
+		{"csv in seconds", FormatCSV, "0,2\n90,6\n300,2"},
+		{"json", FormatJSON, `[{"offset":"0s","replicas":2},{"offset":90,"replicas":6},{"offset":"5m","replicas":2}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.data), tt.format)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("Parse() = %v, want %v", got, want)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("point %d = %v, want %v", i, got[i], want[i])
+				}
+			}
+		})
+	}
+
+	for _, data := range []string{"", "0,2\n0,3", "0,-1", "0,2\nsoon,3", "0,2,1"} {
+		if _, err := Parse([]byte(data), FormatCSV); err == nil {
+			t.Errorf("Parse(%q) expected an error", data)
+		}
+	}
+}
+
+func TestReplayValue(t *testing.T) {
+	spec := shared.ReplayMode{Series: "0,2\n60s,6\n120s,2"}
+	tests := []struct {
+		name   string
+		spec   func(*shared.ReplayMode)
+		offset time.Duration
+		want   float64
+	}{
+		{"interpolates linearly", nil, 30 * time.Second, 4},
+		{"steps", func(s *shared.ReplayMode) { s.Interpolation = InterpolationStep }, 59 * time.Second, 2},
+		{"loops", nil, 2*time.Minute + 30*time.Second, 4},
+		{"loops before the anchor", nil, -90 * time.Second, 4},
+		{"clamps", func(s *shared.ReplayMode) { s.End = EndClamp }, time.Hour, 2},
+		{"clamps before the first point", func(s *shared.ReplayMode) { s.End = EndClamp }, -time.Minute, 2},
+		{"is anchored", func(s *shared.ReplayMode) { s.Anchor = "1970-01-01T00:01:00" }, 90 * time.Second, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := spec
+			if tt.spec != nil {
+				tt.spec(&s)
+			}
+			r, err := New(s)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := r.Value(epoch.Add(tt.offset)); got != tt.want {
+				t.Errorf("Value() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayStep(t *testing.T) {
+	r, err := New(shared.ReplayMode{Series: "0,2\n60s,6\n120s,6", End: EndClamp})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Step(epoch.Add(10 * time.Second)); got != 15*time.Second {
+		t.Errorf("Step() while rising by 4 a minute = %s, want 15s", got)
+	}
+	if got := r.Step(epoch.Add(70 * time.Second)); got != 50*time.Second {
+		t.Errorf("Step() while flat = %s, want until the next point", got)
+	}
+	if got := r.Step(epoch.Add(time.Hour)); got != 0 {
+		t.Errorf("Step() after a clamped series ended = %s, want 0", got)
+	}
+}
+
+func TestNewRejects(t *testing.T) {
+	if _, err := New(shared.ReplayMode{}); !errors.Is(err, ErrNoSeries) {
+		t.Errorf("New() without a series error = %v, want ErrNoSeries", err)
+	}
+	specs := []shared.ReplayMode{
+		{Series: "0,2", End: "bounce"},
+		{Series: "0,2", Interpolation: "cubic"},
+		{Series: "0,2", Format: "xml"},
+		{Series: "0,2", Timezone: "Mars/Olympus"},
+		{Series: "0,2", Anchor: "tomorrow"},
+		{Series: "0,2"},
+		{Series: "0,2\nNaN,4", End: EndClamp},
+		{Series: "0,2\n+Inf,4", End: EndClamp},
+		{Series: "0,2\n1e300,4", End: EndClamp},
+	}
+	for _, s := range specs {
+		if _, err := New(s); err == nil || errors.Is(err, ErrNoSeries) {
+			t.Errorf("New(%+v) error = %v, want it rejected", s, err)
+		}
+	}
+	if _, err := New(shared.ReplayMode{Series: "0,2", End: EndClamp}); err != nil {
+		t.Errorf("New() of a single clamped point error = %v", err)
+	}
+	if _, err := New(shared.ReplayMode{Series: `[{"offset":0,"replicas":2},{"offset":60,"replicas":4}]`,
+		SeriesConfigMapRef: &shared.ConfigMapKeyRef{Name: "traffic", Key: "series.json"}}); err != nil {
+		t.Errorf("New() of a .json key error = %v, want json detected", err)
+	}
+}
@@ -0,0 +1,197 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+// ModeComposite aggregates the targets of the child modes in
+// spec.composite.children.
+const ModeComposite = "composite"
+
+// Aggregations supported by composite mode.
+const (
+	AggregationMin = "min"
+	AggregationMax = "max"
+	AggregationSum = "sum"
+	AggregationAvg = "avg"
+)
+
+type compositeMode struct{}
+
+func init() {
+	Register(compositeMode{})
+}
+
+func (compositeMode) Name() string {
+	return ModeComposite
+}
+
+func (compositeMode) Default(spec *shared.OperatorSpec) {
+	if spec.Composite.Aggregation == "" {
+		spec.Composite.Aggregation = AggregationMax
+	}
+	for i := range spec.Composite.Children {
+		child := &spec.Composite.Children[i]
+		m, err := Lookup(child.Mode)
+		if err != nil {
+			continue
+		}
+		if d, ok := m.(Defaulter); ok {
+			cs := child.Spec()
+			d.Default(&cs)
+			child.SetSpec(cs)
+		}
+	}
+}
+
+func (compositeMode) Validate(spec shared.OperatorSpec) error {
+	var errs []error
+	switch spec.Composite.Aggregation {
+	case AggregationMin, AggregationMax, AggregationSum, AggregationAvg:
+	default:
+		errs = append(errs, fmt.Errorf("composite.aggregation: must be one of min, max, sum or avg, got %q", spec.Composite.Aggregation))
+	}
+	if len(spec.Composite.Children) == 0 {
+		errs = append(errs, errors.New("composite.children: at least one child is required"))
+	}
+	for i, child := range spec.Composite.Children {
+		if child.Mode == ModeComposite {
+			errs = append(errs, fmt.Errorf("composite.children[%d].mode: composites can't be nested", i))
+			continue
+		}
+		m, err := Lookup(child.Mode)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("composite.children[%d].mode: %w", i, err))
+			continue
+		}
+		err = m.Validate(child.Spec())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("composite.children[%d].%w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m compositeMode) Target(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	target, _, err := m.Explain(ctx, spec, clk)
+	return target, err
+}
+
+// Explain evaluates every child. Children which fail are left out of the
+// aggregate, which only fails when no child succeeds.
+func (compositeMode) Explain(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, []Contribution, error) {
+	contributions := make([]Contribution, 0, len(spec.Composite.Children))
+	var targets []int32
+	var errs []error
+	for i, child := range spec.Composite.Children {
+		c := Contribution{Name: child.Name, Mode: child.Mode}
+		if c.Name == "" {
+			c.Name = strconv.Itoa(i)
+		}
+		m, err := Lookup(child.Mode)
+		if err == nil {
+			c.Target, err = m.Target(ctx, child.Spec(), clk)
+		}
+		if err != nil {
+			c.Err = err
+			errs = append(errs, fmt.Errorf("composite child %s: %w", c.Name, err))
+		} else {
+			targets = append(targets, c.Target)
+		}
+		contributions = append(contributions, c)
+	}
+	if len(targets) == 0 {
+		return 0, contributions, errors.Join(errs...)
+	}
+	return aggregate(spec.Composite.Aggregation, targets), contributions, nil
+}
+
+// Forecast aggregates the forecasts of the children. Unlike Explain, it fails
+// if any child can't forecast, as the aggregate of the rest isn't the
+// composite's. The forecast is only as long as the shortest child's.
+func (compositeMode) Forecast(ctx context.Context, spec shared.OperatorSpec, times []time.Time, clk clock.Clock) ([]ForecastPoint, error) {
+	var series [][]ForecastPoint
+	var errs []error
+	n := len(times)
+	for i, child := range spec.Composite.Children {
+		name := child.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		m, err := Lookup(child.Mode)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("composite child %s: %w", name, err))
+			continue
+		}
+		f, ok := m.(Forecaster)
+		if !ok {
+			errs = append(errs, fmt.Errorf("composite child %s: %s: %w", name, child.Mode, ErrNoForecast))
+			continue
+		}
+		points, err := f.Forecast(ctx, child.Spec(), times, clk)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("composite child %s: %w", name, err))
+			continue
+		}
+		series = append(series, points)
+		n = min(n, len(points))
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	points := make([]ForecastPoint, n)
+	targets := make([]int32, len(series))
+	for i := range points {
+		for j, s := range series {
+			targets[j] = s[i].Target
+		}
+		points[i] = ForecastPoint{At: times[i], Target: aggregate(spec.Composite.Aggregation, targets)}
+	}
+	return points, nil
+}
+
+// NextEvaluation is the soonest any child may change.
+func (compositeMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	var next time.Time
+	for _, child := range spec.Composite.Children {
+		m, err := Lookup(child.Mode)
+		if err != nil {
+			continue
+		}
+		t := m.NextEvaluation(child.Spec(), clk)
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+func aggregate(aggregation string, targets []int32) int32 {
+	result := targets[0]
+	var sum int64
+	for _, t := range targets {
+		sum += int64(t)
+		switch aggregation {
+		case AggregationMin:
+			result = min(result, t)
+		case AggregationMax:
+			result = max(result, t)
+		}
+	}
+	switch aggregation {
+	case AggregationSum:
+		return int32(min(sum, math.MaxInt32))
+	case AggregationAvg:
+		return int32(math.Round(float64(sum) / float64(len(targets))))
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+func TestCompositeExplain(t *testing.T) {
+	two, five := int32(2), int32(5)
+	spec := shared.OperatorSpec{
+		Mode: ModeComposite,
+		Composite: shared.CompositeMode{
+			Children: []shared.CompositeChild{
+				{Name: "baseline", Mode: ModeManual, Replicas: &two},
+				{Name: "burst", Mode: ModeManual, Replicas: &five},
+				{Name: "weather", Mode: ModeWeather, Weather: shared.WeatherMode{City: "Melbourne", Country: "AU"}},
+			},
+		},
+	}
+	compositeMode{}.Default(&spec)
+	if spec.Composite.Aggregation != AggregationMax {
+		t.Fatalf("default aggregation = %q, want max", spec.Composite.Aggregation)
+	}
+
+	tests := map[string]int32{
+		AggregationMin: 2,
+		AggregationMax: 5,
+		AggregationSum: 7,
+		AggregationAvg: 4,
+	}
+	for aggregation, want := range tests {
+		t.Run(aggregation, func(t *testing.T) {
+			spec.Composite.Aggregation = aggregation
+			got, contributions, err := compositeMode{}.Explain(context.Background(), spec, fixedClock(time.Now()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("Explain() = %d, want %d", got, want)
+			}
+			if len(contributions) != 3 {
+				t.Fatalf("got %d contributions, want 3", len(contributions))
+			}
+			if contributions[1].Target != 5 || contributions[2].Err == nil {
+				t.Errorf("unexpected contributions %+v", contributions)
+			}
+		})
+	}
+}
+
+func TestCompositeValidate(t *testing.T) {
+	spec := shared.OperatorSpec{
+		Composite: shared.CompositeMode{
+			Aggregation: AggregationSum,
+			Children: []shared.CompositeChild{
+				{Mode: ModeTrig, Trig: shared.TrigMode{Duration: "5m", Min: 1, Max: 4}},
+				{Mode: ModeComposite},
+			},
+		},
+	}
+	if err := (compositeMode{}).Validate(spec); err == nil {
+		t.Error("Validate() admitted a nested composite")
+	}
+	spec.Composite.Children = spec.Composite.Children[:1]
+	if err := (compositeMode{}).Validate(spec); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/externalscaler"
+)
+
+// ModeExternal asks a gRPC scaler at external.address for the target, so
+// modes can be run out of process rather than compiled into the operator.
+const ModeExternal = "external"
+
+const (
+	// defaultExternalTimeout bounds a GetTarget call when external.timeout is
+	// unset.
+	defaultExternalTimeout = 5 * time.Second
+	// defaultExternalInterval is how often the scaler is asked when
+	// external.interval is unset.
+	defaultExternalInterval = 30 * time.Second
+	// minExternalInterval keeps a typo from hammering the scaler.
+	minExternalInterval = time.Second
+)
+
+type externalMode struct{}
+
+func init() {
+	Register(externalMode{})
+}
+
+func (externalMode) Name() string {
+	return ModeExternal
+}
+
+func (externalMode) Validate(spec shared.OperatorSpec) error {
+	e := spec.External
+	var errs []error
+	if e.Address == "" {
+		errs = append(errs, errors.New("external.address: required"))
+	} else if _, _, err := net.SplitHostPort(e.Address); err != nil {
+		errs = append(errs, fmt.Errorf("external.address: must be host:port, %w", err))
+	}
+	if e.Timeout != "" {
+		d, err := time.ParseDuration(e.Timeout)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("external.timeout: %w", err))
+		} else if d <= 0 {
+			errs = append(errs, fmt.Errorf("external.timeout: must be positive, got %s", d))
+		}
+	}
+	if e.Interval != "" {
+		d, err := time.ParseDuration(e.Interval)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("external.interval: %w", err))
+		} else if d < minExternalInterval {
+			errs = append(errs, fmt.Errorf("external.interval: must be at least %s, got %s", minExternalInterval, d))
+		}
+	}
+	if e.TLS != nil {
+		if ref := e.TLS.CASecretRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+			errs = append(errs, errors.New("external.tls.caSecretRef: name and key are required"))
+		}
+		if ref := e.TLS.CertSecretRef; ref != nil && ref.Name == "" {
+			errs = append(errs, errors.New("external.tls.certSecretRef: name is required"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Target calls the scaler. Failures are returned as errors rather than
+// defaulted here, so spec.scaling.fallbackReplicas, or holding the current
+// count, applies while the scaler is unavailable.
+func (externalMode) Target(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	e := spec.External
+	timeout, err := time.ParseDuration(e.Timeout)
+	if err != nil || timeout <= 0 {
+		timeout = defaultExternalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var opts *externalscaler.TLSOptions
+	if e.TLS != nil {
+		opts = &externalscaler.TLSOptions{
+			ServerName:         e.TLS.ServerName,
+			InsecureSkipVerify: e.TLS.InsecureSkipVerify,
+			CA:                 e.TLS.CA,
+			Cert:               e.TLS.Cert,
+			Key:                e.TLS.Key,
+		}
+	}
+	ship := ShipFromContext(ctx)
+	resp, err := externalscaler.GetTarget(ctx, e.Address, opts, &externalscaler.GetTargetRequest{
+		Ship: &externalscaler.ShipRef{Name: ship.Name, Namespace: ship.Namespace},
+		Spec: e.Spec,
+		Time: timestamppb.New(clk.Now()),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("external scaler %s: %w", e.Address, err)
+	}
+	if resp.GetReplicas() < 0 {
+		return 0, fmt.Errorf("external scaler %s: returned %d replicas", e.Address, resp.GetReplicas())
+	}
+	return resp.GetReplicas(), nil
+}
+
+func (externalMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	interval, err := time.ParseDuration(spec.External.Interval)
+	if err != nil || interval < minExternalInterval {
+		interval = defaultExternalInterval
+	}
+	return clk.Now().Add(interval)
+}
@@ -0,0 +1,113 @@
+package scaling
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/externalscaler"
+)
+
+type stubScaler struct {
+	externalscaler.UnimplementedExternalScalerServer
+	got *externalscaler.GetTargetRequest
+}
+
+func (s *stubScaler) GetTarget(_ context.Context, req *externalscaler.GetTargetRequest) (*externalscaler.GetTargetResponse, error) {
+	s.got = req
+	return &externalscaler.GetTargetResponse{Replicas: int32(len(req.GetSpec()["queue"]))}, nil
+}
+
+func TestExternalTarget(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &stubScaler{}
+	srv := grpc.NewServer()
+	externalscaler.RegisterExternalScalerServer(srv, stub)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	spec := shared.OperatorSpec{
+		Mode: ModeExternal,
+		External: shared.ExternalMode{
+			Address: lis.Addr().String(),
+			Spec:    map[string]string{"queue": "orders"},
+		},
+	}
+	if err := (externalMode{}).Validate(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	ctx := WithShip(context.Background(), Ship{Name: "black-pearl", Namespace: "caribbean"})
+	got, err := externalMode{}.Target(ctx, spec, fixedClock(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 6 {
+		t.Errorf("Target() = %d, want 6", got)
+	}
+	if stub.got.GetShip().GetName() != "black-pearl" || stub.got.GetShip().GetNamespace() != "caribbean" {
+		t.Errorf("scaler got ship %v", stub.got.GetShip())
+	}
+	if !stub.got.GetTime().AsTime().Equal(now) {
+		t.Errorf("scaler got time %v, want %v", stub.got.GetTime().AsTime(), now)
+	}
+}
+
+// countingListener counts the connections it accepts.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+func TestExternalTargetReusesTheConnection(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: lis}
+	srv := grpc.NewServer()
+	externalscaler.RegisterExternalScalerServer(srv, &stubScaler{})
+	go func() { _ = srv.Serve(counting) }()
+	defer srv.Stop()
+
+	spec := shared.OperatorSpec{External: shared.ExternalMode{Address: lis.Addr().String()}}
+	for range 3 {
+		if _, err := (externalMode{}).Target(context.Background(), spec, fixedClock(time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := counting.accepted.Load(); n != 1 {
+		t.Errorf("scaler accepted %d connections, want 1", n)
+	}
+}
+
+func TestExternalTargetUnavailable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := lis.Addr().String()
+	lis.Close()
+
+	spec := shared.OperatorSpec{External: shared.ExternalMode{Address: addr, Timeout: "200ms"}}
+	if _, err := (externalMode{}).Target(context.Background(), spec, fixedClock(time.Now())); err == nil {
+		t.Fatal("Target() succeeded against a closed port, want an error")
+	}
+}
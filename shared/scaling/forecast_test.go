@@ -0,0 +1,169 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+func targets(points []ForecastPoint) []int32 {
+	out := make([]int32, len(points))
+	for i, p := range points {
+		out[i] = p.Target
+	}
+	return out
+}
+
+func TestForecastTrig(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	spec := shared.OperatorSpec{
+		Mode:    ModeTrig,
+		Trig:    shared.TrigMode{Duration: "100s", Min: 0, Max: 10},
+		Scaling: shared.ScalingPolicy{MaxReplicas: int32p(8)},
+	}
+	points, err := Forecast(context.Background(), spec, from, from.Add(100*time.Second), 25*time.Second, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if want := []int32{5, 8, 5, 0, 5}; !slices.Equal(targets(points), want) {
+		t.Errorf("Forecast() = %v, want %v clamped to maxReplicas", targets(points), want)
+	}
+	if !points[1].At.Equal(from.Add(25 * time.Second)) {
+		t.Errorf("second point is at %s, want 25s in", points[1].At)
+	}
+}
+
+func TestForecastSchedule(t *testing.T) {
+	spec := shared.OperatorSpec{
+		Mode: ModeSchedule,
+		Schedule: shared.ScheduleMode{Entries: []shared.ScheduleEntry{
+			{Cron: "0 9 * * *", Replicas: 10},
+			{Cron: "0 17 * * *", Replicas: 2},
+		}},
+	}
+	from := time.Date(2025, 5, 1, 6, 0, 0, 0, time.UTC)
+	points, err := Forecast(context.Background(), spec, from, from.Add(24*time.Hour), 4*time.Hour, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	// 06:00, 10:00, 14:00, 18:00, 22:00, 02:00 and 06:00.
+	if want := []int32{2, 10, 10, 2, 2, 2, 2}; !slices.Equal(targets(points), want) {
+		t.Errorf("Forecast() = %v, want %v", targets(points), want)
+	}
+
+	changes := Changes(points)
+	if len(changes) != 3 || !changes[1].At.Equal(from.Add(4*time.Hour)) || changes[2].Target != 2 {
+		t.Errorf("Changes() = %v, want the start, the scale up and the scale down", changes)
+	}
+}
+
+func TestForecastComposite(t *testing.T) {
+	spec := shared.OperatorSpec{
+		Mode: ModeComposite,
+		Composite: shared.CompositeMode{
+			Aggregation: AggregationMax,
+			Children: []shared.CompositeChild{
+				{Mode: ModeManual, Replicas: int32p(4)},
+				{Mode: ModeTrig, Trig: shared.TrigMode{Duration: "100s", Min: 0, Max: 10}},
+			},
+		},
+	}
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	points, err := Forecast(context.Background(), spec, from, from.Add(75*time.Second), 25*time.Second, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if want := []int32{5, 10, 5, 4}; !slices.Equal(targets(points), want) {
+		t.Errorf("Forecast() = %v, want %v", targets(points), want)
+	}
+
+	// The max of the others says nothing of a child reading live metrics.
+	spec.Composite.Children = append(spec.Composite.Children, shared.CompositeChild{
+		Name: "queue", Mode: ModePrometheus, Prometheus: shared.PrometheusMode{ServerURL: "http://prometheus", Query: "up"},
+	})
+	points, err = Forecast(context.Background(), spec, from, from.Add(75*time.Second), 25*time.Second, clock.NewFake(from))
+	if !errors.Is(err, ErrNoForecast) || !strings.Contains(err.Error(), "queue") {
+		t.Errorf("Forecast() = %v, %v, want ErrNoForecast naming the child", points, err)
+	}
+}
+
+func TestForecastWeatherStopsWithTheProvider(t *testing.T) {
+	path := filepath.Join(fakeWeatherDir(t), "weather.json")
+	script := `{"steps": [{"temp": 12}]}`
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := shared.OperatorSpec{Mode: ModeWeather, Weather: shared.WeatherMode{Provider: WeatherProviderFake, URL: path}}
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	points, err := Forecast(context.Background(), spec, from, from.Add(time.Hour), time.Minute, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	// A script without an interval is a single reading, which the forecast
+	// can't see past.
+	if len(points) != 1 || points[0].Target != 12 {
+		t.Errorf("Forecast() = %v, want a single point at 12", points)
+	}
+
+	script = `{"interval": "30m", "steps": [{"temp": 4}, {"temp": 26}]}`
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	points, err = Forecast(context.Background(), spec, from, from.Add(time.Hour), 15*time.Minute, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if want := []int32{4, 4, 26, 26, 4}; !slices.Equal(targets(points), want) {
+		t.Errorf("Forecast() = %v, want %v", targets(points), want)
+	}
+}
+
+func TestForecastRejects(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	manual := shared.OperatorSpec{Mode: ModeManual, Replicas: int32p(1)}
+	if _, err := Forecast(context.Background(), manual, from, from.Add(time.Hour), 0, clock.NewFake(from)); err == nil {
+		t.Error("Forecast() expected an error for a zero step")
+	}
+	if _, err := Forecast(context.Background(), manual, from, from.Add(-time.Hour), time.Minute, clock.NewFake(from)); err == nil {
+		t.Error("Forecast() expected an error for a window ending before it starts")
+	}
+	if _, err := Forecast(context.Background(), manual, from, from.AddDate(1, 0, 0), time.Minute, clock.NewFake(from)); err == nil {
+		t.Error("Forecast() expected an error for too many points")
+	}
+	prom := shared.OperatorSpec{Mode: ModePrometheus}
+	if _, err := Forecast(context.Background(), prom, from, from.Add(time.Hour), time.Minute, clock.NewFake(from)); !errors.Is(err, ErrNoForecast) {
+		t.Errorf("Forecast() error = %v, want ErrNoForecast", err)
+	}
+}
+
+func TestForecastReplay(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	spec := shared.OperatorSpec{
+		Mode: ModeReplay,
+		Replay: shared.ReplayMode{
+			SeriesConfigMapRef: &shared.ConfigMapKeyRef{Name: "traffic", Key: "series.csv"},
+			Series:             "0,2\n60s,6\n120s,2",
+		},
+	}
+	points, err := Forecast(context.Background(), spec, from, from.Add(2*time.Minute), 30*time.Second, clock.NewFake(from))
+	if err != nil {
+		t.Fatalf("Forecast() error = %v", err)
+	}
+	if want := []int32{2, 4, 6, 4, 2}; !slices.Equal(targets(points), want) {
+		t.Errorf("Forecast() = %v, want %v", targets(points), want)
+	}
+
+	spec.Replay.Series = ""
+	if _, err := Forecast(context.Background(), spec, from, from.Add(time.Minute), time.Minute, clock.NewFake(from)); !errors.Is(err, ErrNoForecast) {
+		t.Errorf("Forecast() without the series error = %v, want ErrNoForecast", err)
+	}
+}
@@ -0,0 +1,66 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+// ModeManual targets spec.replicas, which is what `kubectl scale` and
+// autoscalers write through the Ship's scale subresource.
+const ModeManual = "manual"
+
+type manualMode struct{}
+
+func init() {
+	Register(manualMode{})
+}
+
+func (manualMode) Name() string {
+	return ModeManual
+}
+
+func (manualMode) Default(spec *shared.OperatorSpec) {
+	if spec.Replicas == nil {
+		replicas := int32(1)
+		spec.Replicas = &replicas
+	}
+}
+
+func (manualMode) Validate(spec shared.OperatorSpec) error {
+	if spec.Replicas == nil {
+		return errors.New("replicas: required in manual mode")
+	}
+	if *spec.Replicas < 0 {
+		return fmt.Errorf("replicas: must not be negative, got %d", *spec.Replicas)
+	}
+	return nil
+}
+
+func (manualMode) Target(_ context.Context, spec shared.OperatorSpec, _ clock.Clock) (int32, error) {
+	if spec.Replicas == nil {
+		return 0, errors.New("replicas: required in manual mode")
+	}
+	return *spec.Replicas, nil
+}
+
+// Forecast holds spec.replicas.
+func (m manualMode) Forecast(ctx context.Context, spec shared.OperatorSpec, times []time.Time, clk clock.Clock) ([]ForecastPoint, error) {
+	target, err := m.Target(ctx, spec, clk)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ForecastPoint, len(times))
+	for i, t := range times {
+		points[i] = ForecastPoint{At: t, Target: target}
+	}
+	return points, nil
+}
+
+func (manualMode) NextEvaluation(shared.OperatorSpec, clock.Clock) time.Time {
+	return time.Time{}
+}
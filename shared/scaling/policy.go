@@ -0,0 +1,219 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+// defaultStepPeriod applies when a MaxStep is set without a StepPeriod.
+const defaultStepPeriod = time.Minute
+
+// ValidatePolicy reports whether the scaling policy is usable.
+func ValidatePolicy(p shared.ScalingPolicy) error {
+	var errs []error
+	counts := []struct {
+		name string
+		v    *int32
+	}{
+		{"minReplicas", p.MinReplicas},
+		{"maxReplicas", p.MaxReplicas},
+		{"fallbackReplicas", p.FallbackReplicas},
+		{"scaleUp.maxStep", p.ScaleUp.MaxStep},
+		{"scaleDown.maxStep", p.ScaleDown.MaxStep},
+	}
+	for _, c := range counts {
+		if c.v != nil && *c.v < 0 {
+			errs = append(errs, fmt.Errorf("scaling.%s: must not be negative, got %d", c.name, *c.v))
+		}
+	}
+	if p.MinReplicas != nil && p.MaxReplicas != nil && *p.MinReplicas > *p.MaxReplicas {
+		errs = append(errs, fmt.Errorf("scaling.minReplicas (%d) must not be greater than scaling.maxReplicas (%d)", *p.MinReplicas, *p.MaxReplicas))
+	}
+	durations := []struct {
+		name string
+		v    string
+	}{
+		{"leadTime", p.LeadTime},
+		{"scaleUp.stepPeriod", p.ScaleUp.StepPeriod},
+		{"scaleUp.stabilizationWindow", p.ScaleUp.StabilizationWindow},
+		{"scaleDown.stepPeriod", p.ScaleDown.StepPeriod},
+		{"scaleDown.stabilizationWindow", p.ScaleDown.StabilizationWindow},
+	}
+	for _, d := range durations {
+		if d.v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("scaling.%s: %w", d.name, err))
+		} else if parsed < 0 {
+			errs = append(errs, fmt.Errorf("scaling.%s: must not be negative, got %s", d.name, parsed))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type sample struct {
+	at       time.Time
+	replicas int32
+}
+
+// History remembers the recent recommendations and replica counts of a
+// single ship. It is not safe for concurrent use.
+type History struct {
+	recommendations []sample
+	replicas        []sample
+}
+
+// FallbackTarget is the target used when the mode fails to produce one. With
+// no fallback configured the current replica count is held, or a single
+// replica when there is none yet.
+func FallbackTarget(p shared.ScalingPolicy, current int32) int32 {
+	if p.FallbackReplicas != nil {
+		return *p.FallbackReplicas
+	}
+	if current >= 0 {
+		return current
+	}
+	return 1
+}
+
+// ApplyPolicy turns a mode's recommendation into the replica count to apply.
+// It stabilizes the recommendation over the configured windows, limits the
+// step from the current count and finally clamps it to the global bounds.
+// A negative current count means the conscripts do not exist yet, in which
+// case only the bounds apply.
+func ApplyPolicy(p shared.ScalingPolicy, h *History, now time.Time, current, recommended int32) int32 {
+	upWindow := parseDuration(p.ScaleUp.StabilizationWindow)
+	downWindow := parseDuration(p.ScaleDown.StabilizationWindow)
+	upPeriod := stepPeriod(p.ScaleUp)
+	downPeriod := stepPeriod(p.ScaleDown)
+
+	h.recommendations = append(h.recommendations, sample{at: now, replicas: recommended})
+	if current >= 0 {
+		h.replicas = append(h.replicas, sample{at: now, replicas: current})
+	}
+	h.prune(now, max(upWindow, downWindow), max(upPeriod, downPeriod))
+
+	desired := recommended
+	if current >= 0 {
+		// Scale up only as far as every recommendation in the up window
+		// agrees, and down only as far as every one in the down window does.
+		upRec := minSince(h.recommendations, now.Add(-upWindow), recommended)
+		downRec := maxSince(h.recommendations, now.Add(-downWindow), recommended)
+		switch {
+		case current < upRec:
+			desired = upRec
+		case current > downRec:
+			desired = downRec
+		default:
+			desired = current
+		}
+
+		if p.ScaleUp.MaxStep != nil {
+			base := minSince(h.replicas, now.Add(-upPeriod), current)
+			desired = min(desired, base+*p.ScaleUp.MaxStep)
+		}
+		if p.ScaleDown.MaxStep != nil {
+			base := maxSince(h.replicas, now.Add(-downPeriod), current)
+			desired = max(desired, base-*p.ScaleDown.MaxStep)
+		}
+	}
+
+	desired = Clamp(p, desired)
+
+	if current >= 0 && desired != current {
+		h.replicas = append(h.replicas, sample{at: now, replicas: desired})
+	}
+	return desired
+}
+
+// Lead looks spec.Scaling.LeadTime ahead of now so conscripts are started
+// before they are needed. It returns the forecast target at now+leadTime when
+// that is higher than target, the mode's target now, and target otherwise so
+// conscripts are only removed once the fall happens. Modes which can't
+// forecast keep target.
+func Lead(ctx context.Context, spec shared.OperatorSpec, target int32, clk clock.Clock) (int32, error) {
+	lead := LeadTime(spec.Scaling)
+	if lead == 0 {
+		return target, nil
+	}
+	at := clk.Now().Add(lead)
+	points, err := Forecast(ctx, spec, at, at, time.Minute, clk)
+	if errors.Is(err, ErrNoForecast) {
+		return target, nil
+	}
+	if err != nil {
+		return target, err
+	}
+	if len(points) == 0 {
+		return target, nil
+	}
+	return max(target, points[0].Target), nil
+}
+
+// LeadTime parses the policy's lead time, which is zero when unset or invalid.
+func LeadTime(p shared.ScalingPolicy) time.Duration {
+	return parseDuration(p.LeadTime)
+}
+
+// Clamp bounds a target to the policy's min and max replicas, and to zero.
+func Clamp(p shared.ScalingPolicy, target int32) int32 {
+	if p.MaxReplicas != nil {
+		target = min(target, *p.MaxReplicas)
+	}
+	if p.MinReplicas != nil {
+		target = max(target, *p.MinReplicas)
+	}
+	return max(target, 0)
+}
+
+func (h *History) prune(now time.Time, window, period time.Duration) {
+	h.recommendations = since(h.recommendations, now.Add(-window))
+	h.replicas = since(h.replicas, now.Add(-period))
+}
+
+func since(samples []sample, from time.Time) []sample {
+	i := 0
+	for i < len(samples) && samples[i].at.Before(from) {
+		i++
+	}
+	return samples[i:]
+}
+
+func minSince(samples []sample, from time.Time, v int32) int32 {
+	for _, s := range since(samples, from) {
+		v = min(v, s.replicas)
+	}
+	return v
+}
+
+func maxSince(samples []sample, from time.Time, v int32) int32 {
+	for _, s := range since(samples, from) {
+		v = max(v, s.replicas)
+	}
+	return v
+}
+
+func stepPeriod(r shared.ScalingRules) time.Duration {
+	if r.MaxStep == nil {
+		return 0
+	}
+	if d := parseDuration(r.StepPeriod); d > 0 {
+		return d
+	}
+	return defaultStepPeriod
+}
+
+func parseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
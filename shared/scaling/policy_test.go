@@ -0,0 +1,155 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+func int32p(v int32) *int32 {
+	return &v
+}
+
+func TestApplyPolicyBounds(t *testing.T) {
+	p := shared.ScalingPolicy{MinReplicas: int32p(2), MaxReplicas: int32p(8)}
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		recommended int32
+		want        int32
+	}{
+		{"below min", -4, 2},
+		{"within bounds", 5, 5},
+		{"above max", 30, 8},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ApplyPolicy(p, &History{}, now, -1, tc.recommended)
+			if got != tc.want {
+				t.Errorf("ApplyPolicy() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestApplyPolicyNeverNegative(t *testing.T) {
+	got := ApplyPolicy(shared.ScalingPolicy{}, &History{}, time.Now(), 3, -7)
+	if got != 0 {
+		t.Errorf("ApplyPolicy() = %d, want 0", got)
+	}
+}
+
+func TestApplyPolicyMaxStep(t *testing.T) {
+	p := shared.ScalingPolicy{
+		ScaleUp: shared.ScalingRules{MaxStep: int32p(2), StepPeriod: "1m"},
+	}
+	h := &History{}
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	current := int32(1)
+	steps := []struct {
+		after time.Duration
+		want  int32
+	}{
+		{0, 3},
+		{10 * time.Second, 3},
+		{61 * time.Second, 5},
+		{122 * time.Second, 7},
+	}
+	for _, s := range steps {
+		current = ApplyPolicy(p, h, start.Add(s.after), current, 10)
+		if current != s.want {
+			t.Fatalf("after %s ApplyPolicy() = %d, want %d", s.after, current, s.want)
+		}
+	}
+}
+
+func TestApplyPolicyStabilization(t *testing.T) {
+	p := shared.ScalingPolicy{
+		ScaleDown: shared.ScalingRules{StabilizationWindow: "5m"},
+	}
+	h := &History{}
+	start := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	if got := ApplyPolicy(p, h, start, 4, 10); got != 10 {
+		t.Fatalf("scale up ApplyPolicy() = %d, want 10", got)
+	}
+	// A dip inside the window is ignored because 10 was recommended recently.
+	if got := ApplyPolicy(p, h, start.Add(time.Minute), 10, 2); got != 10 {
+		t.Fatalf("dip ApplyPolicy() = %d, want 10", got)
+	}
+	// Once the peak leaves the window the lower recommendations win.
+	if got := ApplyPolicy(p, h, start.Add(6*time.Minute), 10, 2); got != 2 {
+		t.Fatalf("after window ApplyPolicy() = %d, want 2", got)
+	}
+}
+
+func TestFallbackTarget(t *testing.T) {
+	if got := FallbackTarget(shared.ScalingPolicy{FallbackReplicas: int32p(4)}, 9); got != 4 {
+		t.Errorf("FallbackTarget() with fallback = %d, want 4", got)
+	}
+	if got := FallbackTarget(shared.ScalingPolicy{}, 9); got != 9 {
+		t.Errorf("FallbackTarget() holding current = %d, want 9", got)
+	}
+	if got := FallbackTarget(shared.ScalingPolicy{}, -1); got != 1 {
+		t.Errorf("FallbackTarget() without conscripts = %d, want 1", got)
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	if err := ValidatePolicy(shared.ScalingPolicy{MinReplicas: int32p(5), MaxReplicas: int32p(2)}); err == nil {
+		t.Error("expected min > max to be rejected")
+	}
+	if err := ValidatePolicy(shared.ScalingPolicy{ScaleUp: shared.ScalingRules{StabilizationWindow: "soon"}}); err == nil {
+		t.Error("expected an unparsable window to be rejected")
+	}
+	if err := ValidatePolicy(shared.ScalingPolicy{LeadTime: "-2m"}); err == nil {
+		t.Error("expected a negative lead time to be rejected")
+	}
+	if err := ValidatePolicy(shared.ScalingPolicy{MinReplicas: int32p(1), MaxReplicas: int32p(3), LeadTime: "2m"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestLead(t *testing.T) {
+	from := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	spec := shared.OperatorSpec{
+		Mode:    ModeTrig,
+		Trig:    shared.TrigMode{Duration: "100s", Min: 0, Max: 10},
+		Scaling: shared.ScalingPolicy{LeadTime: "25s"},
+	}
+	tests := []struct {
+		name   string
+		offset time.Duration
+		target int32
+		want   int32
+	}{
+		{"scales up ahead of a rise", 0, 5, 10},
+		{"holds ahead of a fall", 25 * time.Second, 10, 10},
+		{"scales down once the fall happens", 50 * time.Second, 5, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lead(context.Background(), spec, tt.target, clock.NewFake(from.Add(tt.offset)))
+			if err != nil {
+				t.Fatalf("Lead() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Lead() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	spec.Scaling.LeadTime = ""
+	if got, _ := Lead(context.Background(), spec, 5, clock.NewFake(from)); got != 5 {
+		t.Errorf("Lead() without a lead time = %d, want the target", got)
+	}
+	spec = shared.OperatorSpec{Mode: ModePrometheus, Scaling: shared.ScalingPolicy{LeadTime: "25s"}}
+	if got, err := Lead(context.Background(), spec, 4, clock.NewFake(from)); got != 4 || err != nil {
+		t.Errorf("Lead() for a mode which can't forecast = %d, %v, want the target", got, err)
+	}
+}
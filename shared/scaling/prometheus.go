@@ -0,0 +1,104 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/prometheus"
+)
+
+// ModePrometheus targets ceil(query result / prometheus.targetPerReplica)
+// replicas, within the mode's own bounds.
+const ModePrometheus = "prometheus"
+
+// defaultPrometheusInterval is how often the query is evaluated when
+// prometheus.interval is unset, matching a typical scrape interval.
+const defaultPrometheusInterval = 30 * time.Second
+
+// minPrometheusInterval keeps a typo from hammering the Prometheus server.
+const minPrometheusInterval = 5 * time.Second
+
+// prometheusQueryTimeout bounds a single query so a slow server can't stall
+// the reconcile.
+const prometheusQueryTimeout = 10 * time.Second
+
+type prometheusMode struct{}
+
+func init() {
+	Register(prometheusMode{})
+}
+
+func (prometheusMode) Name() string {
+	return ModePrometheus
+}
+
+func (prometheusMode) Validate(spec shared.OperatorSpec) error {
+	p := spec.Prometheus
+	var errs []error
+	if p.ServerURL == "" {
+		errs = append(errs, errors.New("prometheus.serverURL: required"))
+	} else if u, err := url.Parse(p.ServerURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("prometheus.serverURL: must be an http(s) URL, got %q", p.ServerURL))
+	}
+	if p.Query == "" {
+		errs = append(errs, errors.New("prometheus.query: required"))
+	}
+	if p.TargetPerReplica == "" {
+		errs = append(errs, errors.New("prometheus.targetPerReplica: required"))
+	} else if v, err := strconv.ParseFloat(p.TargetPerReplica, 64); err != nil || v <= 0 || math.IsInf(v, 0) {
+		errs = append(errs, fmt.Errorf("prometheus.targetPerReplica: must be a positive number, got %q", p.TargetPerReplica))
+	}
+	if p.MinReplicas < 0 {
+		errs = append(errs, fmt.Errorf("prometheus.minReplicas: must not be negative, got %d", p.MinReplicas))
+	}
+	if p.MaxReplicas < 1 {
+		errs = append(errs, fmt.Errorf("prometheus.maxReplicas: must be at least 1, got %d", p.MaxReplicas))
+	} else if p.MinReplicas > p.MaxReplicas {
+		errs = append(errs, fmt.Errorf("prometheus.minReplicas (%d) must not be greater than prometheus.maxReplicas (%d)", p.MinReplicas, p.MaxReplicas))
+	}
+	if p.Interval != "" {
+		d, err := time.ParseDuration(p.Interval)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("prometheus.interval: %w", err))
+		} else if d < minPrometheusInterval {
+			errs = append(errs, fmt.Errorf("prometheus.interval: must be at least %s, got %s", minPrometheusInterval, d))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (prometheusMode) Target(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	p := spec.Prometheus
+	perReplica, err := strconv.ParseFloat(p.TargetPerReplica, 64)
+	if err != nil || perReplica <= 0 {
+		return 0, fmt.Errorf("prometheus.targetPerReplica: must be a positive number, got %q", p.TargetPerReplica)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout)
+	defer cancel()
+	value, err := prometheus.Client{BaseURL: p.ServerURL}.Query(ctx, p.Query, clk.Now())
+	if err != nil {
+		return 0, fmt.Errorf("prometheus: %w", err)
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("prometheus: query returned %v", value)
+	}
+
+	replicas := math.Ceil(value / perReplica)
+	return int32(max(float64(p.MinReplicas), min(replicas, float64(p.MaxReplicas)))), nil
+}
+
+func (prometheusMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	interval, err := time.ParseDuration(spec.Prometheus.Interval)
+	if err != nil || interval < minPrometheusInterval {
+		interval = defaultPrometheusInterval
+	}
+	return clk.Now().Add(interval)
+}
@@ -0,0 +1,94 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+func TestPrometheusTarget(t *testing.T) {
+	var value string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,%q]}]}}`, value)
+	}))
+	defer srv.Close()
+
+	spec := shared.OperatorSpec{
+		Mode: ModePrometheus,
+		Prometheus: shared.PrometheusMode{
+			ServerURL:        srv.URL,
+			Query:            `sum(rate(http_requests_total{job="conscript"}[1m]))`,
+			TargetPerReplica: "50",
+			MinReplicas:      1,
+			MaxReplicas:      10,
+		},
+	}
+	mode, err := Lookup(ModePrometheus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mode.Validate(spec); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		value string
+		want  int32
+	}{
+		{"0", 1},
+		{"120", 3},
+		{"150", 3},
+		{"150.5", 4},
+		{"9000", 10},
+	}
+	for _, tt := range tests {
+		value = tt.value
+		got, err := mode.Target(context.Background(), spec, fixedClock(time.Unix(1700000000, 0)))
+		if err != nil {
+			t.Fatalf("Target() with %s: %v", tt.value, err)
+		}
+		if got != tt.want {
+			t.Errorf("Target() with %s = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPrometheusValidate(t *testing.T) {
+	valid := shared.PrometheusMode{
+		ServerURL:        "http://prometheus:9090",
+		Query:            "sum(up)",
+		TargetPerReplica: "0.5",
+		MaxReplicas:      5,
+	}
+	tests := map[string]func(p *shared.PrometheusMode){
+		"relative url":      func(p *shared.PrometheusMode) { p.ServerURL = "prometheus:9090" },
+		"no query":          func(p *shared.PrometheusMode) { p.Query = "" },
+		"zero target":       func(p *shared.PrometheusMode) { p.TargetPerReplica = "0" },
+		"unparsable target": func(p *shared.PrometheusMode) { p.TargetPerReplica = "lots" },
+		"min above max":     func(p *shared.PrometheusMode) { p.MinReplicas = 6 },
+		"short interval":    func(p *shared.PrometheusMode) { p.Interval = "1s" },
+	}
+	if err := (prometheusMode{}).Validate(shared.OperatorSpec{Prometheus: valid}); err != nil {
+		t.Fatalf("Validate() of a valid spec: %v", err)
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := valid
+			mutate(&p)
+			if err := (prometheusMode{}).Validate(shared.OperatorSpec{Prometheus: p}); err == nil {
+				t.Error("Validate() succeeded, want an error")
+			}
+		})
+	}
+}
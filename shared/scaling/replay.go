@@ -0,0 +1,79 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/replay"
+)
+
+// ModeReplay plays back the recorded series spec.replay reads from a
+// ConfigMap.
+const ModeReplay = "replay"
+
+type replayMode struct{}
+
+func init() {
+	Register(replayMode{})
+}
+
+func (replayMode) Name() string {
+	return ModeReplay
+}
+
+// Validate checks the options, and the series once the operator has read it.
+func (replayMode) Validate(spec shared.OperatorSpec) error {
+	ref := spec.Replay.SeriesConfigMapRef
+	if ref == nil || ref.Name == "" || ref.Key == "" {
+		return errors.New("replay.seriesConfigMapRef: a ConfigMap name and key are required")
+	}
+	_, err := replay.New(spec.Replay)
+	if err != nil && !errors.Is(err, replay.ErrNoSeries) {
+		return fmt.Errorf("replay.%w", err)
+	}
+	return nil
+}
+
+func (replayMode) Target(_ context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	r, err := replay.New(spec.Replay)
+	if err != nil {
+		return 0, fmt.Errorf("replay.%w", err)
+	}
+	return r.Target(clk.Now()), nil
+}
+
+// Forecast plays the series forward. A missing series, such as an optional
+// ConfigMap which doesn't exist, has nothing to forecast.
+func (replayMode) Forecast(_ context.Context, spec shared.OperatorSpec, times []time.Time, _ clock.Clock) ([]ForecastPoint, error) {
+	r, err := replay.New(spec.Replay)
+	if errors.Is(err, replay.ErrNoSeries) {
+		return nil, fmt.Errorf("replay.%w: %w", err, ErrNoForecast)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay.%w", err)
+	}
+	points := make([]ForecastPoint, len(times))
+	for i, t := range times {
+		points[i] = ForecastPoint{At: t, Target: r.Target(t)}
+	}
+	return points, nil
+}
+
+// NextEvaluation is when the series next moves by a replica, see
+// replay.Replay.Step. A clamped series which has ended never changes.
+func (replayMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	now := clk.Now()
+	r, err := replay.New(spec.Replay)
+	if err != nil {
+		return now.Add(time.Minute)
+	}
+	step := r.Step(now)
+	if step == 0 {
+		return time.Time{}
+	}
+	return now.Add(max(step, time.Second))
+}
@@ -0,0 +1,184 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+// ScalingMode computes the target number of conscripts for a ship. Modes are
+// selected by the value of OperatorSpec.Mode and only read their own block of
+// the spec.
+type ScalingMode interface {
+	// Name is the spec.mode value that selects this mode.
+	Name() string
+	// Validate reports whether the mode's block of the spec is usable.
+	Validate(spec shared.OperatorSpec) error
+	// Target computes the desired conscript count.
+	Target(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error)
+	// NextEvaluation reports when Target may next return a different value.
+	// The zero time means the target only changes with the spec.
+	NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time
+}
+
+// Defaulter is implemented by modes which can fill in unset fields of their
+// block of the spec.
+type Defaulter interface {
+	Default(spec *shared.OperatorSpec)
+}
+
+// Contribution is the target one part of a combined mode came up with.
+type Contribution struct {
+	Name   string
+	Mode   string
+	Target int32
+	Err    error
+}
+
+// Explainer is implemented by modes which combine other modes. Explain returns
+// the same target as Target along with what each part contributed to it.
+type Explainer interface {
+	Explain(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, []Contribution, error)
+}
+
+// ForecastPoint is the target a mode predicts for At.
+type ForecastPoint struct {
+	At     time.Time `json:"at"`
+	Target int32     `json:"target"`
+}
+
+// Forecaster is implemented by modes which can predict their target ahead of
+// time. Forecast returns a point for each of times, which are in order, and
+// may stop short when the mode can't see that far ahead.
+type Forecaster interface {
+	Forecast(ctx context.Context, spec shared.OperatorSpec, times []time.Time, _ clock.Clock) ([]ForecastPoint, error)
+}
+
+// MaxForecastPoints bounds the length of a forecast.
+const MaxForecastPoints = 10_000
+
+// ErrNoForecast is returned by Forecast for modes which don't implement
+// Forecaster, e.g. those reading live metrics.
+var ErrNoForecast = errors.New("mode can't forecast")
+
+// Forecast predicts the target of the mode selected by spec at from and every
+// step after it up to and including to, clamped to the policy bounds. The
+// rate limits of the policy depend on the history of the Ship and aren't
+// applied. clk is the time the forecast is made at, which may differ from
+// from.
+func Forecast(ctx context.Context, spec shared.OperatorSpec, from, to time.Time, step time.Duration, clk clock.Clock) ([]ForecastPoint, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("forecast step must be positive, got %s", step)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("forecast ends at %s, before it starts at %s", to, from)
+	}
+	if n := to.Sub(from) / step; n >= MaxForecastPoints {
+		return nil, fmt.Errorf("forecast would have %d points, at most %d are allowed", n+1, MaxForecastPoints)
+	}
+	mode, err := Lookup(spec.Mode)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := mode.(Forecaster)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", spec.Mode, ErrNoForecast)
+	}
+
+	var times []time.Time
+	for t := from; !t.After(to); t = t.Add(step) {
+		times = append(times, t)
+	}
+	points, err := f.Forecast(ctx, spec, times, clk)
+	if err != nil {
+		return nil, err
+	}
+	for i := range points {
+		points[i].Target = Clamp(spec.Scaling, points[i].Target)
+	}
+	return points, nil
+}
+
+// Changes keeps the first point of a forecast and those where the target
+// differs from the point before.
+func Changes(points []ForecastPoint) []ForecastPoint {
+	var changes []ForecastPoint
+	for i, p := range points {
+		if i == 0 || p.Target != points[i-1].Target {
+			changes = append(changes, p)
+		}
+	}
+	return changes
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]ScalingMode{}
+)
+
+// Register makes a mode available under its Name. Registering the same name
+// twice panics, mirroring database/sql drivers.
+func Register(m ScalingMode) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if m == nil {
+		panic("scaling: Register mode is nil")
+	}
+	if _, dup := registry[m.Name()]; dup {
+		panic("scaling: Register called twice for mode " + m.Name())
+	}
+	registry[m.Name()] = m
+}
+
+// Lookup returns the mode registered under name.
+func Lookup(name string) (ScalingMode, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	m, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scaling mode %q, expected one of %v", name, modes())
+	}
+	return m, nil
+}
+
+// Modes returns the sorted names of all registered modes.
+func Modes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return modes()
+}
+
+func modes() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Ship identifies the Ship a mode is evaluated for.
+type Ship struct {
+	Name      string
+	Namespace string
+}
+
+type shipKey struct{}
+
+// WithShip returns a context carrying the Ship being evaluated, for modes which
+// pass it on to other services.
+func WithShip(ctx context.Context, ship Ship) context.Context {
+	return context.WithValue(ctx, shipKey{}, ship)
+}
+
+// ShipFromContext returns the Ship stored by WithShip, or the zero Ship.
+func ShipFromContext(ctx context.Context) Ship {
+	ship, _ := ctx.Value(shipKey{}).(Ship)
+	return ship
+}
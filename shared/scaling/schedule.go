@@ -0,0 +1,90 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/schedule"
+)
+
+// ModeSchedule targets the replica count of the most recently fired cron entry
+// in spec.schedule.
+const ModeSchedule = "schedule"
+
+type scheduleMode struct{}
+
+func init() {
+	Register(scheduleMode{})
+}
+
+func (scheduleMode) Name() string {
+	return ModeSchedule
+}
+
+func (scheduleMode) Validate(spec shared.OperatorSpec) error {
+	if len(spec.Schedule.Entries) == 0 {
+		return errors.New("schedule.entries: at least one entry is required")
+	}
+	for i, e := range spec.Schedule.Entries {
+		if e.Replicas < 0 {
+			return fmt.Errorf("schedule.entries[%d].replicas: must not be negative, got %d", i, e.Replicas)
+		}
+	}
+	_, err := schedule.New(spec.Schedule)
+	if err != nil {
+		return fmt.Errorf("schedule.%w", err)
+	}
+	return nil
+}
+
+func (scheduleMode) Target(_ context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	s, err := schedule.New(spec.Schedule)
+	if err != nil {
+		return 0, fmt.Errorf("schedule.%w", err)
+	}
+	active, ok := s.Active(clk.Now())
+	if !ok {
+		return 0, errors.New("schedule: no entry has fired in the last year")
+	}
+	return active.Replicas, nil
+}
+
+// Forecast walks the entries firing between the first and last of times.
+func (scheduleMode) Forecast(_ context.Context, spec shared.OperatorSpec, times []time.Time, _ clock.Clock) ([]ForecastPoint, error) {
+	if len(times) == 0 {
+		return nil, nil
+	}
+	s, err := schedule.New(spec.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("schedule.%w", err)
+	}
+	active, ok := s.Active(times[0])
+	if !ok {
+		return nil, errors.New("schedule: no entry has fired in the last year")
+	}
+
+	changes := s.Between(times[0], times[len(times)-1], MaxForecastPoints)
+	target := active.Replicas
+	points := make([]ForecastPoint, len(times))
+	for i, t := range times {
+		for len(changes) > 0 && !changes[0].At.After(t) {
+			target = changes[0].Replicas
+			changes = changes[1:]
+		}
+		points[i] = ForecastPoint{At: t, Target: target}
+	}
+	return points, nil
+}
+
+// NextEvaluation is when the next entry fires.
+func (scheduleMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	s, err := schedule.New(spec.Schedule)
+	if err != nil {
+		return clk.Now().Add(time.Minute)
+	}
+	return s.Next(clk.Now())
+}
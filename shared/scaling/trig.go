@@ -0,0 +1,114 @@
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/trig"
+)
+
+const ModeTrig = "trig"
+
+// Defaults applied to an unset trig block, matching the example in the README.
+const (
+	defaultTrigDuration = "300s"
+	defaultTrigMin      = 1
+	defaultTrigMax      = 6
+)
+
+type trigMode struct{}
+
+func init() {
+	Register(trigMode{})
+}
+
+func (trigMode) Name() string {
+	return ModeTrig
+}
+
+func (trigMode) Default(spec *shared.OperatorSpec) {
+	if spec.Trig.Duration == "" {
+		spec.Trig.Duration = defaultTrigDuration
+	}
+	if spec.Trig.Max == 0 {
+		if spec.Trig.Min == 0 {
+			spec.Trig.Min = defaultTrigMin
+		}
+		spec.Trig.Max = max(spec.Trig.Min, defaultTrigMax)
+	}
+}
+
+func (trigMode) Validate(spec shared.OperatorSpec) error {
+	d, err := time.ParseDuration(spec.Trig.Duration)
+	if err != nil {
+		return fmt.Errorf("trig.duration: %w", err)
+	}
+	if d < time.Second {
+		return fmt.Errorf("trig.duration: must be at least 1s, got %s", d)
+	}
+	if spec.Trig.Min < 0 {
+		return fmt.Errorf("trig.min: must not be negative, got %d", spec.Trig.Min)
+	}
+	if spec.Trig.Min > spec.Trig.Max {
+		return fmt.Errorf("trig.min (%d) must not be greater than trig.max (%d)", spec.Trig.Min, spec.Trig.Max)
+	}
+	_, err = trig.FromSpec(spec.Trig)
+	return err
+}
+
+func (trigMode) Target(_ context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	args, err := trig.FromSpec(spec.Trig)
+	if err != nil {
+		return 0, err
+	}
+	fv, err := trig.GetValue(args, clk.Now())
+	if err != nil {
+		return 0, err
+	}
+	return int32(fv), nil
+}
+
+func (trigMode) Forecast(_ context.Context, spec shared.OperatorSpec, times []time.Time, _ clock.Clock) ([]ForecastPoint, error) {
+	args, err := trig.FromSpec(spec.Trig)
+	if err != nil {
+		return nil, err
+	}
+	wave, err := trig.NewWave(args)
+	if err != nil {
+		return nil, err
+	}
+	points := make([]ForecastPoint, len(times))
+	for i, t := range times {
+		points[i] = ForecastPoint{At: t, Target: int32(wave.Value(t))}
+	}
+	return points, nil
+}
+
+// NextEvaluation returns the shortest time the wave can take to move by one
+// replica, see trig.Step. For a sine that is where it is steepest, the period
+// divided by pi times the replica range, so a 5m wave between 1 and 6 replicas
+// is re-evaluated every ~19s. Square and pulse waves wait for their next edge.
+func (trigMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	now := clk.Now()
+	if spec.Trig.Max == spec.Trig.Min {
+		return time.Time{}
+	}
+	args, err := trig.FromSpec(spec.Trig)
+	if err != nil || spec.Trig.Max < spec.Trig.Min {
+		return now.Add(time.Minute)
+	}
+	step, err := trig.Step(args, now)
+	if err != nil {
+		return now.Add(time.Minute)
+	}
+	if step == 0 {
+		return time.Time{}
+	}
+	if step < time.Second {
+		step = time.Second
+	}
+	return now.Add(step)
+}
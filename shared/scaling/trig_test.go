@@ -0,0 +1,42 @@
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+func TestTrigFollowsAWholeCycleOnAFakeClock(t *testing.T) {
+	spec := shared.OperatorSpec{Trig: shared.TrigMode{Duration: "300s", Min: 1, Max: 6}}
+	clk := clock.NewFake(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	end := clk.Now().Add(5 * time.Minute)
+
+	lowest, highest := int32(100), int32(0)
+	evaluations := 0
+	for clk.Now().Before(end) {
+		got, err := trigMode{}.Target(context.Background(), spec, clk)
+		if err != nil {
+			t.Fatalf("Target() error = %v", err)
+		}
+		lowest, highest = min(lowest, got), max(highest, got)
+
+		next := trigMode{}.NextEvaluation(spec, clk)
+		if !next.After(clk.Now()) {
+			t.Fatalf("NextEvaluation() = %s, not after %s", next, clk.Now())
+		}
+		clk.Set(next)
+		evaluations++
+	}
+
+	// The value is truncated, so max is only reached at the very peak.
+	if lowest != 1 || highest < 5 {
+		t.Errorf("a full cycle covered %d to %d replicas, want 1 to at least 5", lowest, highest)
+	}
+	// A 5m wave between 1 and 6 is re-evaluated every ~19s.
+	if evaluations < 15 || evaluations > 17 {
+		t.Errorf("a full cycle took %d evaluations, want ~16", evaluations)
+	}
+}
@@ -0,0 +1,355 @@
+package scaling
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/openmeteo"
+	"github.com/socialviolation/freyr/shared/openweather"
+	"github.com/socialviolation/freyr/shared/weather"
+)
+
+const ModeWeather = "weather"
+
+// defaultWeatherPollInterval is how often the current temperature is re-read
+// when weather.pollInterval is unset. OpenWeather refreshes roughly every 10m.
+const defaultWeatherPollInterval = 5 * time.Minute
+
+// minWeatherPollInterval keeps a typo from hammering the OpenWeather API.
+const minWeatherPollInterval = 30 * time.Second
+
+// weatherTimeout bounds geocoding and reading the current weather together.
+const weatherTimeout = 10 * time.Second
+
+// Weather signals a Ship can scale on.
+const (
+	WeatherSignalTemp      = "temp"
+	WeatherSignalFeelsLike = "feels_like"
+	WeatherSignalHumidity  = "humidity"
+	WeatherSignalWind      = "wind"
+	WeatherSignalClouds    = "clouds"
+)
+
+// Mappings from a weather signal to a replica count. Direct uses the signal as
+// the count, linear and inverse scale the input range onto the output range
+// (inverse targets outputMax at inputMin) and thresholds steps through a
+// table.
+const (
+	WeatherMappingDirect     = "direct"
+	WeatherMappingLinear     = "linear"
+	WeatherMappingInverse    = "inverse"
+	WeatherMappingThresholds = "thresholds"
+)
+
+// Weather providers. OpenWeather needs an API key, Open-Meteo doesn't and its
+// url may point at a self-hosted instance. Fake serves a weather.Script from
+// its url, which may also be a file path.
+const (
+	WeatherProviderOpenWeather = "openweather"
+	WeatherProviderOpenMeteo   = "openmeteo"
+	WeatherProviderFake        = "fake"
+)
+
+type weatherMode struct{}
+
+func init() {
+	Register(weatherMode{})
+}
+
+func (weatherMode) Name() string {
+	return ModeWeather
+}
+
+func (weatherMode) Default(spec *shared.OperatorSpec) {
+	if spec.Weather.Provider == "" {
+		spec.Weather.Provider = WeatherProviderOpenWeather
+	}
+	if spec.Weather.Signal == "" {
+		spec.Weather.Signal = WeatherSignalTemp
+	}
+	if spec.Weather.Mapping.Type == "" {
+		spec.Weather.Mapping.Type = WeatherMappingDirect
+	}
+}
+
+func (weatherMode) Validate(spec shared.OperatorSpec) error {
+	var errs []error
+	switch spec.Weather.Provider {
+	case "", WeatherProviderOpenWeather:
+		if spec.Weather.APIKey == "" && spec.Weather.APIKeySecretRef == nil {
+			errs = append(errs, errors.New("weather.apiKeySecretRef: required"))
+		}
+	case WeatherProviderOpenMeteo:
+	case WeatherProviderFake:
+		if spec.Weather.URL == "" {
+			errs = append(errs, errors.New("weather.url: required by the fake provider"))
+		} else if FakeWeatherDir == "" && !isHTTPURL(spec.Weather.URL) {
+			errs = append(errs, fmt.Errorf("weather.url: must be an http or https URL, file scripts are disabled, got %q", spec.Weather.URL))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("weather.provider: must be one of openweather, openmeteo or fake, got %q", spec.Weather.Provider))
+	}
+	if spec.Weather.Provider != WeatherProviderFake {
+		if spec.Weather.City == "" {
+			errs = append(errs, errors.New("weather.city: required"))
+		}
+		if spec.Weather.Country == "" {
+			errs = append(errs, errors.New("weather.country: required"))
+		}
+	}
+	if spec.Weather.URL != "" && spec.Weather.Provider != WeatherProviderFake && !isHTTPURL(spec.Weather.URL) {
+		errs = append(errs, fmt.Errorf("weather.url: must be an http or https URL, got %q", spec.Weather.URL))
+	}
+	if spec.Weather.GeocodingURL != "" {
+		if spec.Weather.Provider != WeatherProviderOpenMeteo {
+			errs = append(errs, errors.New("weather.geocodingURL: only used by the openmeteo provider"))
+		} else if !isHTTPURL(spec.Weather.GeocodingURL) {
+			errs = append(errs, fmt.Errorf("weather.geocodingURL: must be an http or https URL, got %q", spec.Weather.GeocodingURL))
+		}
+	}
+	if ref := spec.Weather.APIKeySecretRef; ref != nil && (ref.Name == "" || ref.Key == "") {
+		errs = append(errs, errors.New("weather.apiKeySecretRef: name and key are required"))
+	}
+	if spec.Weather.PollInterval != "" {
+		d, err := time.ParseDuration(spec.Weather.PollInterval)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("weather.pollInterval: %w", err))
+		} else if d < minWeatherPollInterval {
+			errs = append(errs, fmt.Errorf("weather.pollInterval: must be at least %s, got %s", minWeatherPollInterval, d))
+		}
+	}
+	switch spec.Weather.Signal {
+	case "", WeatherSignalTemp, WeatherSignalFeelsLike, WeatherSignalHumidity, WeatherSignalWind, WeatherSignalClouds:
+	default:
+		errs = append(errs, fmt.Errorf("weather.signal: must be one of temp, feels_like, humidity, wind or clouds, got %q", spec.Weather.Signal))
+	}
+	errs = append(errs, validateWeatherMapping(spec.Weather.Mapping)...)
+	return errors.Join(errs...)
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validateWeatherMapping(m shared.WeatherMapping) []error {
+	var errs []error
+	switch m.Type {
+	case "", WeatherMappingDirect:
+	case WeatherMappingLinear, WeatherMappingInverse:
+		lo, errLo := strconv.ParseFloat(m.InputMin, 64)
+		if errLo != nil {
+			errs = append(errs, fmt.Errorf("weather.mapping.inputMin: must be a number, got %q", m.InputMin))
+		}
+		hi, errHi := strconv.ParseFloat(m.InputMax, 64)
+		if errHi != nil {
+			errs = append(errs, fmt.Errorf("weather.mapping.inputMax: must be a number, got %q", m.InputMax))
+		}
+		if errLo == nil && errHi == nil && lo >= hi {
+			errs = append(errs, fmt.Errorf("weather.mapping.inputMin (%s) must be less than inputMax (%s)", m.InputMin, m.InputMax))
+		}
+		if m.OutputMin < 0 {
+			errs = append(errs, fmt.Errorf("weather.mapping.outputMin: must not be negative, got %d", m.OutputMin))
+		}
+		if m.OutputMin > m.OutputMax {
+			errs = append(errs, fmt.Errorf("weather.mapping.outputMin (%d) must not be greater than outputMax (%d)", m.OutputMin, m.OutputMax))
+		}
+	case WeatherMappingThresholds:
+		if len(m.Thresholds) == 0 {
+			errs = append(errs, errors.New("weather.mapping.thresholds: at least one threshold is required"))
+		}
+		if m.OutputMin < 0 {
+			errs = append(errs, fmt.Errorf("weather.mapping.outputMin: must not be negative, got %d", m.OutputMin))
+		}
+		prev := math.Inf(-1)
+		for i, t := range m.Thresholds {
+			above, err := strconv.ParseFloat(t.Above, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("weather.mapping.thresholds[%d].above: must be a number, got %q", i, t.Above))
+				continue
+			}
+			if above <= prev {
+				errs = append(errs, fmt.Errorf("weather.mapping.thresholds[%d].above: must be greater than the previous threshold", i))
+			}
+			prev = above
+			if t.Replicas < 0 {
+				errs = append(errs, fmt.Errorf("weather.mapping.thresholds[%d].replicas: must not be negative, got %d", i, t.Replicas))
+			}
+		}
+	default:
+		errs = append(errs, fmt.Errorf("weather.mapping.type: must be one of direct, linear, inverse or thresholds, got %q", m.Type))
+	}
+	return errs
+}
+
+func (weatherMode) Target(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) (int32, error) {
+	provider, err := weatherProvider(spec.Weather, clk)
+	if err != nil {
+		return 0, err
+	}
+	l := weather.Location{
+		Country: spec.Weather.Country,
+		City:    spec.Weather.City,
+	}
+	ctx, cancel := context.WithTimeout(ctx, weatherTimeout)
+	defer cancel()
+	conditions, err := provider.Conditions(ctx, l)
+	if err != nil {
+		return 0, err
+	}
+	value, err := weatherSignal(conditions, spec.Weather.Signal)
+	if err != nil {
+		return 0, err
+	}
+	return mapWeather(spec.Weather.Mapping, value)
+}
+
+// errNoWeatherAPIKey is returned for OpenWeather without a key, which the
+// operator resolves from weather.apiKeySecretRef and redacts from the spec it
+// hands to the captain.
+var errNoWeatherAPIKey = errors.New("weather: no API key available, is weather.apiKeySecretRef resolvable?")
+
+// FakeWeatherDir is the directory the fake provider may read file scripts
+// from. Left empty, as in the captain, it only reads http(s) URLs, so a Ship
+// can't read the files of the process evaluating it.
+var FakeWeatherDir string
+
+// openMeteoClients holds a client per pair of Open-Meteo forecast and
+// geocoding urls, so each keeps its caches between reconciles.
+var openMeteoClients sync.Map
+
+// Forecast maps the provider's forecast, each reading holding until the next.
+// The forecast stops where the provider's does. Providers without one can't
+// forecast, and nor can OpenWeather without its key, as in the captain.
+func (weatherMode) Forecast(ctx context.Context, spec shared.OperatorSpec, times []time.Time, clk clock.Clock) ([]ForecastPoint, error) {
+	if len(times) == 0 {
+		return nil, nil
+	}
+	provider, err := weatherProvider(spec.Weather, clk)
+	if errors.Is(err, errNoWeatherAPIKey) {
+		return nil, fmt.Errorf("weather provider %s without an API key: %w", WeatherProviderOpenWeather, ErrNoForecast)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, ok := provider.(weather.Forecaster)
+	if !ok {
+		return nil, fmt.Errorf("weather provider %s: %w", spec.Weather.Provider, ErrNoForecast)
+	}
+	l := weather.Location{
+		Country: spec.Weather.Country,
+		City:    spec.Weather.City,
+	}
+	ctx, cancel := context.WithTimeout(ctx, weatherTimeout)
+	defer cancel()
+	readings, err := f.Forecast(ctx, l, times[0], times[len(times)-1])
+	if err != nil {
+		return nil, err
+	}
+	if len(readings) == 0 {
+		return nil, nil
+	}
+
+	// The last reading holds for as long as the ones before it did.
+	last := readings[len(readings)-1].At
+	if n := len(readings); n > 1 {
+		last = last.Add(last.Sub(readings[n-2].At))
+	}
+	points := make([]ForecastPoint, 0, len(times))
+	r := 0
+	for _, t := range times {
+		if t.After(last) {
+			break
+		}
+		for r+1 < len(readings) && !readings[r+1].At.After(t) {
+			r++
+		}
+		value, err := weatherSignal(readings[r].Conditions, spec.Weather.Signal)
+		if err != nil {
+			return nil, err
+		}
+		target, err := mapWeather(spec.Weather.Mapping, value)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, ForecastPoint{At: t, Target: target})
+	}
+	return points, nil
+}
+
+func weatherProvider(w shared.WeatherMode, clk clock.Clock) (weather.Provider, error) {
+	switch w.Provider {
+	case "", WeatherProviderOpenWeather:
+		if w.APIKey == "" {
+			return nil, errNoWeatherAPIKey
+		}
+		return openweather.Provider{Client: openweather.DefaultClient, APIKey: w.APIKey}, nil
+	case WeatherProviderOpenMeteo:
+		c, _ := openMeteoClients.LoadOrStore(w.URL+"|"+w.GeocodingURL, &openmeteo.Client{BaseURL: w.URL, GeocodingURL: w.GeocodingURL})
+		return c.(*openmeteo.Client), nil
+	case WeatherProviderFake:
+		return weather.Fake{Source: w.URL, Dir: FakeWeatherDir, Clock: clk}, nil
+	}
+	return nil, fmt.Errorf("weather.provider: unknown provider %q", w.Provider)
+}
+
+func weatherSignal(c weather.Conditions, signal string) (float64, error) {
+	switch signal {
+	case "", WeatherSignalTemp:
+		return c.Temp, nil
+	case WeatherSignalFeelsLike:
+		return c.FeelsLike, nil
+	case WeatherSignalHumidity:
+		return c.Humidity, nil
+	case WeatherSignalWind:
+		return c.WindSpeed, nil
+	case WeatherSignalClouds:
+		return c.Clouds, nil
+	}
+	return 0, fmt.Errorf("weather.signal: unknown signal %q", signal)
+}
+
+// mapWeather converts a signal reading into a replica count. Readings outside
+// the input range are clamped to it, and the count is never negative.
+func mapWeather(m shared.WeatherMapping, value float64) (int32, error) {
+	if errs := validateWeatherMapping(m); len(errs) > 0 {
+		return 0, errors.Join(errs...)
+	}
+	switch m.Type {
+	case WeatherMappingLinear, WeatherMappingInverse:
+		lo, _ := strconv.ParseFloat(m.InputMin, 64)
+		hi, _ := strconv.ParseFloat(m.InputMax, 64)
+		frac := (min(max(value, lo), hi) - lo) / (hi - lo)
+		if m.Type == WeatherMappingInverse {
+			frac = 1 - frac
+		}
+		return m.OutputMin + int32(math.Round(frac*float64(m.OutputMax-m.OutputMin))), nil
+	case WeatherMappingThresholds:
+		target := m.OutputMin
+		for _, t := range m.Thresholds {
+			above, _ := strconv.ParseFloat(t.Above, 64)
+			if value < above {
+				break
+			}
+			target = t.Replicas
+		}
+		return target, nil
+	}
+	return max(int32(value), 0), nil
+}
+
+func (weatherMode) NextEvaluation(spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	interval, err := time.ParseDuration(spec.Weather.PollInterval)
+	if err != nil || interval < minWeatherPollInterval {
+		interval = defaultWeatherPollInterval
+	}
+	return clk.Now().Add(interval)
+}
@@ -0,0 +1,207 @@
+package scaling
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/openmeteo"
+	"github.com/socialviolation/freyr/shared/openweather"
+)
+
+func TestMapWeather(t *testing.T) {
+	linear := shared.WeatherMapping{Type: WeatherMappingLinear, InputMin: "-10", InputMax: "30", OutputMin: 1, OutputMax: 9}
+	inverse := linear
+	inverse.Type = WeatherMappingInverse
+	thresholds := shared.WeatherMapping{
+		Type:      WeatherMappingThresholds,
+		OutputMin: 1,
+		Thresholds: []shared.WeatherThreshold{
+			{Above: "10", Replicas: 3},
+			{Above: "20", Replicas: 6},
+			{Above: "30.5", Replicas: 10},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		mapping shared.WeatherMapping
+		value   float64
+		want    int32
+	}{
+		{"direct", shared.WeatherMapping{}, 17.8, 17},
+		{"direct below zero", shared.WeatherMapping{Type: WeatherMappingDirect}, -4, 0},
+		{"linear at input min", linear, -10, 1},
+		{"linear midpoint", linear, 10, 5},
+		{"linear clamps below", linear, -40, 1},
+		{"linear clamps above", linear, 45, 9},
+		{"inverse at input min", inverse, -10, 9},
+		{"inverse at input max", inverse, 30, 1},
+		{"thresholds below the first", thresholds, -2, 1},
+		{"thresholds on a boundary", thresholds, 20, 6},
+		{"thresholds between", thresholds, 25, 6},
+		{"thresholds above the last", thresholds, 31, 10},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := mapWeather(tc.mapping, tc.value)
+			if err != nil {
+				t.Fatalf("mapWeather() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("mapWeather(%v) = %d, want %d", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestWeatherSignal(t *testing.T) {
+	c := openweather.Conditions{Temp: 21, FeelsLike: 19, Humidity: 60, WindSpeed: 4.5, Clouds: 75}
+	cases := map[string]float64{
+		"":                     21,
+		WeatherSignalTemp:      21,
+		WeatherSignalFeelsLike: 19,
+		WeatherSignalHumidity:  60,
+		WeatherSignalWind:      4.5,
+		WeatherSignalClouds:    75,
+	}
+	for signal, want := range cases {
+		got, err := weatherSignal(c, signal)
+		if err != nil {
+			t.Fatalf("weatherSignal(%q) error = %v", signal, err)
+		}
+		if got != want {
+			t.Errorf("weatherSignal(%q) = %v, want %v", signal, got, want)
+		}
+	}
+	if _, err := weatherSignal(c, "pressure"); err == nil {
+		t.Error("weatherSignal(pressure) expected an error")
+	}
+}
+
+func TestValidateWeatherMapping(t *testing.T) {
+	base := shared.OperatorSpec{Weather: shared.WeatherMode{
+		City:    "Melbourne",
+		Country: "AU",
+		APIKey:  "xxx",
+	}}
+	cases := []struct {
+		name    string
+		weather func(w *shared.WeatherMode)
+		wantErr bool
+	}{
+		{"defaults", func(w *shared.WeatherMode) {}, false},
+		{"unknown signal", func(w *shared.WeatherMode) { w.Signal = "pressure" }, true},
+		{"unknown mapping", func(w *shared.WeatherMode) { w.Mapping.Type = "log" }, true},
+		{"linear with inverted range", func(w *shared.WeatherMode) {
+			w.Mapping = shared.WeatherMapping{Type: WeatherMappingLinear, InputMin: "30", InputMax: "10", OutputMax: 4}
+		}, true},
+		{"linear with unparsable bound", func(w *shared.WeatherMode) {
+			w.Mapping = shared.WeatherMapping{Type: WeatherMappingLinear, InputMin: "cold", InputMax: "10", OutputMax: 4}
+		}, true},
+		{"thresholds out of order", func(w *shared.WeatherMode) {
+			w.Mapping = shared.WeatherMapping{Type: WeatherMappingThresholds, Thresholds: []shared.WeatherThreshold{
+				{Above: "20", Replicas: 2}, {Above: "10", Replicas: 4},
+			}}
+		}, true},
+		{"wind thresholds", func(w *shared.WeatherMode) {
+			w.Signal = WeatherSignalWind
+			w.Mapping = shared.WeatherMapping{Type: WeatherMappingThresholds, Thresholds: []shared.WeatherThreshold{
+				{Above: "5", Replicas: 2}, {Above: "12.5", Replicas: 4},
+			}}
+		}, false},
+		{"openmeteo geocoding url", func(w *shared.WeatherMode) {
+			w.Provider = WeatherProviderOpenMeteo
+			w.GeocodingURL = "https://geocoding.example.com"
+		}, false},
+		{"geocoding url without openmeteo", func(w *shared.WeatherMode) { w.GeocodingURL = "https://geocoding.example.com" }, true},
+		{"geocoding url without a scheme", func(w *shared.WeatherMode) {
+			w.Provider = WeatherProviderOpenMeteo
+			w.GeocodingURL = "geocoding.example.com"
+		}, true},
+		{"fake script url", func(w *shared.WeatherMode) {
+			w.Provider = WeatherProviderFake
+			w.URL = "http://weather-script.default/melbourne.json"
+		}, false},
+		{"fake script file without a directory", func(w *shared.WeatherMode) {
+			w.Provider = WeatherProviderFake
+			w.URL = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+		}, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			spec := base
+			tc.weather(&spec.Weather)
+			err := weatherMode{}.Validate(spec)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// fakeWeatherDir allows fake scripts from a temporary directory for the test.
+func fakeWeatherDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	prev := FakeWeatherDir
+	FakeWeatherDir = dir
+	t.Cleanup(func() { FakeWeatherDir = prev })
+	return dir
+}
+
+func TestWeatherTargetFromFakeProvider(t *testing.T) {
+	path := filepath.Join(fakeWeatherDir(t), "weather.json")
+	script := `{"interval": "1h", "steps": [{"temp": 4}, {"temp": 26}]}`
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := shared.OperatorSpec{Weather: shared.WeatherMode{
+		Provider: WeatherProviderFake,
+		URL:      path,
+		Mapping:  shared.WeatherMapping{Type: WeatherMappingInverse, InputMin: "0", InputMax: "30", OutputMin: 1, OutputMax: 7},
+	}}
+	if err := (weatherMode{}).Validate(spec); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	cases := []struct {
+		at   time.Time
+		want int32
+	}{
+		{time.Date(2025, 5, 1, 0, 30, 0, 0, time.UTC), 6},
+		{time.Date(2025, 5, 1, 1, 30, 0, 0, time.UTC), 2},
+	}
+	for _, tc := range cases {
+		got, err := weatherMode{}.Target(context.Background(), spec, fixedClock(tc.at))
+		if err != nil {
+			t.Fatalf("Target() error = %v", err)
+		}
+		if got != tc.want {
+			t.Errorf("Target() at %s = %d, want %d", tc.at.Format(time.Kitchen), got, tc.want)
+		}
+	}
+}
+
+func TestOpenMeteoProviderURLs(t *testing.T) {
+	p, err := weatherProvider(shared.WeatherMode{Provider: WeatherProviderOpenMeteo, URL: "http://forecast.local"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := p.(*openmeteo.Client); c.BaseURL != "http://forecast.local" || c.GeocodingURL != "" {
+		t.Errorf("client = %s, %s, want the forecast url and the default geocoding url", c.BaseURL, c.GeocodingURL)
+	}
+
+	p, err = weatherProvider(shared.WeatherMode{
+		Provider: WeatherProviderOpenMeteo, URL: "http://forecast.local", GeocodingURL: "http://geocoding.local",
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := p.(*openmeteo.Client); c.GeocodingURL != "http://geocoding.local" {
+		t.Errorf("geocoding url = %s, want http://geocoding.local", c.GeocodingURL)
+	}
+}
@@ -0,0 +1,164 @@
+// Package schedule resolves the replica count of a list of cron entries. Each
+// entry holds its replica count from the moment its cron expression fires until
+// another entry fires, so "0 9 * * 1-5" at 10 and "0 17 * * 1-5" at 2 describe
+// business hours.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+// Change is the firing of an entry, which sets the replica count at a point
+// in time.
+type Change struct {
+	Name     string    `json:"name,omitempty"`
+	Cron     string    `json:"cron"`
+	At       time.Time `json:"at"`
+	Replicas int32     `json:"replicas"`
+}
+
+type compiled struct {
+	shared.ScheduleEntry
+	sched cron.Schedule
+	loc   *time.Location
+}
+
+type Schedule struct {
+	entries []compiled
+}
+
+// lookbacks are the windows searched, in turn, for the most recent firing.
+// Most schedules fire daily or weekly, so the short windows keep the search
+// cheap while the last one covers yearly expressions.
+var lookbacks = []time.Duration{
+	time.Hour,
+	25 * time.Hour,
+	8 * 24 * time.Hour,
+	32 * 24 * time.Hour,
+	367 * 24 * time.Hour,
+}
+
+// New parses the entries of the spec. Cron expressions use the standard five
+// fields or descriptors such as @daily. The spec's timezone is the default for
+// entries without one, and UTC when empty.
+func New(spec shared.ScheduleMode) (*Schedule, error) {
+	defaultLoc, err := time.LoadLocation(spec.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone: %w", err)
+	}
+
+	s := &Schedule{entries: make([]compiled, 0, len(spec.Entries))}
+	for i, e := range spec.Entries {
+		// @every fires relative to whenever it is asked, so it has no
+		// most recent firing to resolve.
+		if strings.HasPrefix(e.Cron, "@every") {
+			return nil, fmt.Errorf("entries[%d].cron: @every is not supported", i)
+		}
+		sched, err := cron.ParseStandard(e.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("entries[%d].cron: %w", i, err)
+		}
+		loc := defaultLoc
+		if e.Timezone != "" {
+			loc, err = time.LoadLocation(e.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("entries[%d].timezone: %w", i, err)
+			}
+		}
+		s.entries = append(s.entries, compiled{ScheduleEntry: e, sched: sched, loc: loc})
+	}
+	return s, nil
+}
+
+// Active returns the entry which fired most recently at or before now. When
+// two entries fire at the same time the later one in the list wins. It
+// reports false if no entry has fired within the last year.
+func (s *Schedule) Active(now time.Time) (Change, bool) {
+	for _, lookback := range lookbacks {
+		var active Change
+		found := false
+		for _, e := range s.entries {
+			at, ok := e.last(now, lookback)
+			if ok && (!found || !at.Before(active.At)) {
+				active = e.change(at)
+				found = true
+			}
+		}
+		if found {
+			return active, true
+		}
+	}
+	return Change{}, false
+}
+
+// Upcoming returns up to n firings after now in time order.
+func (s *Schedule) Upcoming(now time.Time, n int) []Change {
+	return s.firings(now, time.Time{}, n)
+}
+
+// firings returns up to n firings after now in time order, stopping after
+// until unless it is zero.
+func (s *Schedule) firings(now, until time.Time, n int) []Change {
+	next := make([]time.Time, len(s.entries))
+	for i, e := range s.entries {
+		next[i] = e.sched.Next(now.In(e.loc))
+	}
+
+	changes := make([]Change, 0, min(n, upcomingCapacity))
+	for len(changes) < n {
+		i := -1
+		for j, at := range next {
+			if at.IsZero() {
+				continue
+			}
+			if i < 0 || at.Before(next[i]) {
+				i = j
+			}
+		}
+		if i < 0 || (!until.IsZero() && next[i].After(until)) {
+			break
+		}
+		changes = append(changes, s.entries[i].change(next[i]))
+		next[i] = s.entries[i].sched.Next(next[i])
+	}
+	return changes
+}
+
+// Between returns the firings after from up to and including to in time
+// order, at most n of them. Entries firing at the same time are in list
+// order, so applying them in turn leaves the one Active would pick.
+func (s *Schedule) Between(from, to time.Time, n int) []Change {
+	return s.firings(from, to, n)
+}
+
+// upcomingCapacity caps what firings preallocates for large n.
+const upcomingCapacity = 64
+
+// Next returns when the next entry fires after now, or the zero time if none
+// ever does.
+func (s *Schedule) Next(now time.Time) time.Time {
+	changes := s.Upcoming(now, 1)
+	if len(changes) == 0 {
+		return time.Time{}
+	}
+	return changes[0].At
+}
+
+// last returns the final firing of the entry in (now-lookback, now].
+func (e compiled) last(now time.Time, lookback time.Duration) (time.Time, bool) {
+	var last time.Time
+	for at := e.sched.Next(now.Add(-lookback).In(e.loc)); !at.IsZero() && !at.After(now); at = e.sched.Next(at) {
+		last = at
+	}
+	return last, !last.IsZero()
+}
+
+func (e compiled) change(at time.Time) Change {
+	return Change{Name: e.Name, Cron: e.Cron, At: at, Replicas: e.Replicas}
+}
@@ -0,0 +1,112 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+func businessHours(t *testing.T) *Schedule {
+	t.Helper()
+	s, err := New(shared.ScheduleMode{
+		Timezone: "Australia/Melbourne",
+		Entries: []shared.ScheduleEntry{
+			{Name: "business hours", Cron: "0 9 * * 1-5", Replicas: 10},
+			{Name: "nights", Cron: "0 17 * * 1-5", Replicas: 2},
+			{Name: "weekends", Cron: "0 0 * * 6", Replicas: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestActive(t *testing.T) {
+	mel, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip("timezone data unavailable:", err)
+	}
+	s := businessHours(t)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"monday morning", time.Date(2025, 3, 3, 9, 30, 0, 0, mel), "business hours"},
+		{"on the hour", time.Date(2025, 3, 3, 17, 0, 0, 0, mel), "nights"},
+		{"before opening", time.Date(2025, 3, 4, 8, 59, 0, 0, mel), "nights"},
+		{"saturday", time.Date(2025, 3, 8, 12, 0, 0, 0, mel), "weekends"},
+		{"monday before opening", time.Date(2025, 3, 10, 8, 0, 0, 0, mel), "weekends"},
+		{"in utc", time.Date(2025, 3, 3, 23, 0, 0, 0, time.UTC), "business hours"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := s.Active(tt.now)
+			if !ok {
+				t.Fatal("no active entry")
+			}
+			if got.Name != tt.want {
+				t.Errorf("Active() = %q, want %q", got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	mel, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip("timezone data unavailable:", err)
+	}
+	s := businessHours(t)
+
+	got := s.Upcoming(time.Date(2025, 3, 7, 12, 0, 0, 0, mel), 3)
+	want := []time.Time{
+		time.Date(2025, 3, 7, 17, 0, 0, 0, mel),
+		time.Date(2025, 3, 8, 0, 0, 0, 0, mel),
+		time.Date(2025, 3, 10, 9, 0, 0, 0, mel),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Upcoming() returned %d changes, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].At.Equal(want[i]) {
+			t.Errorf("Upcoming()[%d].At = %s, want %s", i, got[i].At, want[i])
+		}
+	}
+}
+
+func TestNewRejectsInvalidEntries(t *testing.T) {
+	for _, spec := range []shared.ScheduleMode{
+		{Entries: []shared.ScheduleEntry{{Cron: "not a cron"}}},
+		{Entries: []shared.ScheduleEntry{{Cron: "@every 1h"}}},
+		{Entries: []shared.ScheduleEntry{{Cron: "@daily", Timezone: "Mars/Olympus"}}},
+		{Timezone: "Mars/Olympus", Entries: []shared.ScheduleEntry{{Cron: "@daily"}}},
+	} {
+		if _, err := New(spec); err == nil {
+			t.Errorf("New(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestBetween(t *testing.T) {
+	mel, err := time.LoadLocation("Australia/Melbourne")
+	if err != nil {
+		t.Skip("timezone data unavailable:", err)
+	}
+	s := businessHours(t)
+
+	from := time.Date(2025, 3, 7, 12, 0, 0, 0, mel)
+	got := s.Between(from, time.Date(2025, 3, 10, 9, 0, 0, 0, mel), 10)
+	if len(got) != 3 || got[2].Name != "business hours" {
+		t.Errorf("Between() = %v, want the 3 firings up to and including Monday 9am", got)
+	}
+	if got := s.Between(from, from.Add(time.Hour), 10); len(got) != 0 {
+		t.Errorf("Between() = %v for an hour without firings, want none", got)
+	}
+	if got := s.Between(from, from.AddDate(0, 1, 0), 4); len(got) != 4 {
+		t.Errorf("Between() returned %d firings, want it capped at 4", len(got))
+	}
+}
@@ -0,0 +1,177 @@
+package trig
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Chart formats understood by RendererFor.
+const (
+	FormatASCII = "ascii"
+	FormatSVG   = "svg"
+	FormatPNG   = "png"
+	FormatJSON  = "json"
+)
+
+// ChartOptions size and position a chart. Zero values take the renderer's
+// default size and the period containing now.
+type ChartOptions struct {
+	// Width and Height are in the renderer's units: characters for ASCII,
+	// pixels for SVG and PNG. Width is also how many points are sampled.
+	Width  int
+	Height int
+	// Window is the span of time drawn, it defaults to one period.
+	Window time.Duration
+	// From is where the window starts. It defaults to the start of the period
+	// containing now, or for a custom Window to Window/2 before now.
+	From time.Time
+}
+
+// Point is the value of the wave at Time.
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Chart is the wave sampled across a window, ready to be drawn by a Renderer.
+type Chart struct {
+	From time.Time
+	To   time.Time
+	// Now is marked on the chart when it falls within the window.
+	Now    time.Time
+	Width  int
+	Height int
+	// Min and Max bound the value axis.
+	Min    float64
+	Max    float64
+	Points []Point
+}
+
+// NowIndex is the index of the point Now falls on, or -1 when it is outside
+// the window.
+func (c Chart) NowIndex() int {
+	if c.Now.Before(c.From) || !c.Now.Before(c.To) || len(c.Points) == 0 {
+		return -1
+	}
+	step := c.To.Sub(c.From) / time.Duration(len(c.Points))
+	if step <= 0 {
+		return 0
+	}
+	return min(int(c.Now.Sub(c.From)/step), len(c.Points)-1)
+}
+
+// Renderer draws a Chart.
+type Renderer interface {
+	// ContentType is the MIME type of what Render writes.
+	ContentType() string
+	// Size is the default width and height.
+	Size() (width, height int)
+	Render(w io.Writer, c Chart) error
+}
+
+var renderers = map[string]Renderer{
+	FormatASCII: ASCII{},
+	FormatSVG:   SVG{},
+	FormatPNG:   PNG{},
+	FormatJSON:  JSON{},
+}
+
+// Formats lists the chart formats.
+func Formats() []string {
+	formats := make([]string, 0, len(renderers))
+	for f := range renderers {
+		formats = append(formats, f)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// RendererFor returns the Renderer for a chart format.
+func RendererFor(format string) (Renderer, error) {
+	r, ok := renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown chart format %q, expected one of %v", format, Formats())
+	}
+	return r, nil
+}
+
+// NewChart samples the wave once per point across the window described by
+// opts, taking unset sizes from r. The cost depends on the size of the chart,
+// not the length of the period.
+func NewChart(wv Wave, now time.Time, r Renderer, opts ChartOptions) (Chart, error) {
+	width, height := r.Size()
+	if opts.Width != 0 {
+		width = opts.Width
+	}
+	if opts.Height != 0 {
+		height = opts.Height
+	}
+	if width < 2 || height < 2 {
+		return Chart{}, fmt.Errorf("chart must be at least 2x2, got %dx%d", width, height)
+	}
+	window := opts.Window
+	if window < 0 {
+		return Chart{}, fmt.Errorf("chart window must be positive, got %s", window)
+	}
+	from := opts.From
+	if from.IsZero() {
+		if window == 0 {
+			from = wv.Start(now)
+		} else {
+			from = now.Add(-window / 2)
+		}
+	}
+	if window == 0 {
+		window = wv.Period()
+	}
+
+	c := Chart{
+		From:   from,
+		To:     from.Add(window),
+		Now:    now,
+		Width:  width,
+		Height: height,
+		Min:    float64(wv.Min),
+		Max:    float64(wv.Max),
+		Points: make([]Point, width),
+	}
+	if c.Min == c.Max {
+		c.Min--
+		c.Max++
+	}
+	step := window / time.Duration(width)
+	for i := range c.Points {
+		t := from.Add(time.Duration(i) * step)
+		c.Points[i] = Point{Time: t, Value: wv.Value(t)}
+	}
+	return c, nil
+}
+
+// Render samples the wave and draws it in format.
+func Render(w io.Writer, format string, wv Wave, now time.Time, opts ChartOptions) error {
+	r, err := RendererFor(format)
+	if err != nil {
+		return err
+	}
+	c, err := NewChart(wv, now, r, opts)
+	if err != nil {
+		return err
+	}
+	return r.Render(w, c)
+}
+
+// RenderChart draws the period containing now as ASCII, marking now on it.
+func RenderChart(a Args, now time.Time) string {
+	wv, err := NewWave(a)
+	if err != nil {
+		return "could not render chart"
+	}
+	sb := &strings.Builder{}
+	if err := Render(sb, FormatASCII, wv, now, ChartOptions{}); err != nil {
+		return "could not render chart"
+	}
+	return sb.String()
+}
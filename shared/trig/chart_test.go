@@ -0,0 +1,195 @@
+package trig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewChartWindow(t *testing.T) {
+	wv, err := NewWave(Args{Duration: "300s", Min: 1, Max: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC)
+
+	c, err := NewChart(wv, now, ASCII{}, ChartOptions{})
+	if err != nil {
+		t.Fatalf("NewChart() error = %v", err)
+	}
+	if want := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC); !c.From.Equal(want) || !c.To.Equal(want.Add(5*time.Minute)) {
+		t.Errorf("the default window is %s to %s, want the period from %s", c.From, c.To, want)
+	}
+	if len(c.Points) != 120 || c.Height != 12 {
+		t.Errorf("the default ASCII chart is %dx%d, want 120x12", len(c.Points), c.Height)
+	}
+	if got := c.NowIndex(); got != 40 {
+		t.Errorf("NowIndex() = %d, want 40", got)
+	}
+
+	c, err = NewChart(wv, now, SVG{}, ChartOptions{Width: 60, Height: 50, Window: time.Hour})
+	if err != nil {
+		t.Fatalf("NewChart() error = %v", err)
+	}
+	if !c.From.Equal(now.Add(-30*time.Minute)) || c.NowIndex() != 30 {
+		t.Errorf("a custom window starts at %s with now at %d, want it centred on now", c.From, c.NowIndex())
+	}
+
+	c, err = NewChart(wv, now, SVG{}, ChartOptions{From: now.Add(time.Minute), Window: time.Minute})
+	if err != nil {
+		t.Fatalf("NewChart() error = %v", err)
+	}
+	if c.NowIndex() != -1 {
+		t.Errorf("NowIndex() = %d for a window after now, want -1", c.NowIndex())
+	}
+
+	if _, err := NewChart(wv, now, SVG{}, ChartOptions{Width: 1}); err == nil {
+		t.Error("NewChart() expected an error for a chart 1 wide")
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	wv, err := NewWave(Args{Duration: "300s", Min: 1, Max: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC)
+	render := func(format string, opts ChartOptions) []byte {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		if err := Render(buf, format, wv, now, opts); err != nil {
+			t.Fatalf("Render(%s) error = %v", format, err)
+		}
+		return buf.Bytes()
+	}
+
+	ascii := string(render(FormatASCII, ChartOptions{Width: 40, Height: 8}))
+	rows := strings.Split(strings.TrimSuffix(ascii, "\n"), "\n")
+	if len(rows) != 8 || strings.Count(ascii, "#") != 1 {
+		t.Errorf("ASCII chart has %d rows and %d markers, want 8 and 1:\n%s", len(rows), strings.Count(ascii, "#"), ascii)
+	}
+
+	svg := string(render(FormatSVG, ChartOptions{}))
+	for _, want := range []string{`<svg `, `width="720"`, `<polyline`, `stroke-dasharray`, `</svg>`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG chart is missing %s", want)
+		}
+	}
+
+	img, err := png.Decode(bytes.NewReader(render(FormatPNG, ChartOptions{Width: 300, Height: 100})))
+	if err != nil {
+		t.Fatalf("PNG chart does not decode: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 100 {
+		t.Errorf("PNG chart is %dx%d, want 300x100", b.Dx(), b.Dy())
+	}
+	if got := img.At(100, 0); got != nowColour {
+		t.Errorf("PNG chart has %v where now is marked, want %v", got, nowColour)
+	}
+
+	var s series
+	if err := json.Unmarshal(render(FormatJSON, ChartOptions{Width: 5}), &s); err != nil {
+		t.Fatalf("JSON chart does not decode: %v", err)
+	}
+	if len(s.Datapoints) != 5 || s.Now != now.UnixMilli() {
+		t.Fatalf("JSON chart = %+v, want 5 datapoints and now", s)
+	}
+	if first := s.Datapoints[0]; first[0] != 3.5 || int64(first[1]) != s.From {
+		t.Errorf("first datapoint = %v, want 3.5 at the start of the window", first)
+	}
+
+	if err := Render(&bytes.Buffer{}, "gif", wv, now, ChartOptions{}); err == nil {
+		t.Error("Render(gif) expected an error")
+	}
+}
+
+// failingWriter accepts n bytes, then fails every write after them.
+type failingWriter struct {
+	n      int
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, fmt.Errorf("write %d: disk full", w.writes)
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestSVGRenderReturnsTheFirstWriteError(t *testing.T) {
+	wv, err := NewWave(Args{Duration: "300s", Min: 1, Max: 6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 200, 2000} {
+		w := &failingWriter{n: n}
+		err := Render(w, FormatSVG, wv, time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC), ChartOptions{})
+		if err == nil {
+			t.Fatalf("Render() after %d bytes error = nil, want the write error", n)
+		}
+		if want := fmt.Sprintf("write %d: disk full", w.writes); err.Error() != want {
+			t.Errorf("Render() after %d bytes error = %v, want %q and no writes after it", n, err, want)
+		}
+	}
+}
+
+// periods the chart benchmarks are run over, from a minute to a week.
+var benchmarkPeriods = []string{"1m", "1h", "24h", "168h"}
+
+func TestChartCostIsIndependentOfPeriod(t *testing.T) {
+	now := time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC)
+	allocs := map[string]float64{}
+	for _, d := range benchmarkPeriods {
+		a := Args{Duration: d, Min: 1, Max: 20}
+		allocs[d] = testing.AllocsPerRun(20, func() {
+			RenderChart(a, now)
+		})
+	}
+	for _, d := range benchmarkPeriods {
+		if allocs[d] != allocs[benchmarkPeriods[0]] {
+			t.Errorf("rendering a %s period made %v allocations, a %s period %v", d, allocs[d], benchmarkPeriods[0], allocs[benchmarkPeriods[0]])
+		}
+	}
+}
+
+func BenchmarkRenderChart(b *testing.B) {
+	now := time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC)
+	for _, d := range benchmarkPeriods {
+		a := Args{Duration: d, Min: 1, Max: 20}
+		b.Run(d, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				RenderChart(a, now)
+			}
+		})
+	}
+}
+
+func BenchmarkRender(b *testing.B) {
+	now := time.Date(2025, 5, 1, 0, 1, 40, 0, time.UTC)
+	for _, format := range Formats() {
+		for _, d := range []string{benchmarkPeriods[0], benchmarkPeriods[len(benchmarkPeriods)-1]} {
+			wv, err := NewWave(Args{Duration: d, Min: 1, Max: 20})
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(format+"/"+d, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := Render(io.Discard, format, wv, now, ChartOptions{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
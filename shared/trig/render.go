@@ -0,0 +1,278 @@
+package trig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ASCII draws the chart as text, one column per point with a value axis down
+// the left. Now is marked by a column of | with # where it meets the wave.
+type ASCII struct{}
+
+func (ASCII) ContentType() string {
+	return "text/plain; charset=utf-8"
+}
+
+func (ASCII) Size() (int, int) {
+	return 120, 12
+}
+
+func (ASCII) Render(w io.Writer, c Chart) error {
+	axisPadding := max(len(strconv.Itoa(int(c.Max))), len(strconv.Itoa(int(c.Min)))) + 1
+	nowIndex := c.NowIndex()
+
+	grid := bytes.Repeat([]byte{' '}, c.Width*c.Height)
+	if nowIndex >= 0 {
+		for row := 0; row < c.Height; row++ {
+			grid[row*c.Width+nowIndex] = '|'
+		}
+	}
+	for i, p := range c.Points {
+		row := int(math.Round(c.scale(p.Value, 0, float64(c.Height-1))))
+		if i == nowIndex {
+			grid[row*c.Width+i] = '#'
+		} else {
+			grid[row*c.Width+i] = '*'
+		}
+	}
+
+	sb := strings.Builder{}
+	sb.Grow(c.Height * (axisPadding + c.Width + 1))
+	for row := c.Height - 1; row >= 0; row-- {
+		label := strconv.Itoa(int(math.Round(translate(float64(row), 0, float64(c.Height-1), c.Min, c.Max))))
+		sb.WriteString(label)
+		sb.WriteString(strings.Repeat(" ", max(axisPadding-len(label), 0)))
+		sb.Write(grid[row*c.Width : (row+1)*c.Width])
+		sb.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Chart colours shared by the SVG and PNG renderers.
+var (
+	waveColour = color.RGBA{R: 0x25, G: 0x63, B: 0xeb, A: 0xff}
+	nowColour  = color.RGBA{R: 0xdc, G: 0x26, B: 0x26, A: 0xff}
+	gridColour = color.RGBA{R: 0xd1, G: 0xd5, B: 0xdb, A: 0xff}
+)
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// SVG draws the chart as a scalable image with the value axis labelled, sized
+// in pixels. It can be inlined into HTML.
+type SVG struct{}
+
+// svgGutter is the space left of the plot for the axis labels.
+const svgGutter = 40
+
+func (SVG) ContentType() string {
+	return "image/svg+xml"
+}
+
+func (SVG) Size() (int, int) {
+	return 720, 200
+}
+
+func (SVG) Render(w io.Writer, c Chart) error {
+	top, bottom := 8.0, float64(c.Height-8)
+	left, right := float64(svgGutter), float64(c.Width-1)
+	x := func(i int) float64 {
+		return translate(float64(i), 0, float64(len(c.Points)-1), left, right)
+	}
+	y := func(v float64) float64 {
+		return c.scale(v, bottom, top)
+	}
+
+	sw := &stickyWriter{w: w}
+	sw.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		c.Width, c.Height, c.Width, c.Height)
+	sw.printf(`<title>%s to %s</title>`, c.From.Format("2006-01-02 15:04:05 MST"), c.To.Format("2006-01-02 15:04:05 MST"))
+	for _, v := range []float64{c.Min, (c.Min + c.Max) / 2, c.Max} {
+		sw.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, left, y(v), right, y(v), hex(gridColour))
+		sw.printf(`<text x="%d" y="%.1f" font-family="monospace" font-size="11" text-anchor="end" dominant-baseline="middle">%s</text>`,
+			svgGutter-6, y(v), strconv.FormatFloat(v, 'f', -1, 64))
+	}
+
+	points := make([]byte, 0, len(c.Points)*12)
+	for i, p := range c.Points {
+		if i > 0 {
+			points = append(points, ' ')
+		}
+		points = strconv.AppendFloat(points, x(i), 'f', 1, 64)
+		points = append(points, ',')
+		points = strconv.AppendFloat(points, y(p.Value), 'f', 1, 64)
+	}
+	sw.printf(`<polyline fill="none" stroke="%s" stroke-width="2" points="`, hex(waveColour))
+	sw.write(points)
+	sw.printf(`"/>`)
+
+	if i := c.NowIndex(); i >= 0 {
+		sw.printf(`<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-dasharray="4 3"/>`,
+			x(i), top, x(i), bottom, hex(nowColour))
+		sw.printf(`<circle cx="%.1f" cy="%.1f" r="4" fill="%s"><title>%s: %.2f</title></circle>`,
+			x(i), y(c.Points[i].Value), hex(nowColour), c.Now.Format("15:04:05"), c.Points[i].Value)
+	}
+	sw.printf("</svg>")
+	return sw.err
+}
+
+// stickyWriter remembers the first error writing to w and skips the writes
+// after it, so a renderer checks for an error once at the end.
+type stickyWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (sw *stickyWriter) printf(format string, args ...any) {
+	if sw.err == nil {
+		_, sw.err = fmt.Fprintf(sw.w, format, args...)
+	}
+}
+
+func (sw *stickyWriter) write(p []byte) {
+	if sw.err == nil {
+		_, sw.err = sw.w.Write(p)
+	}
+}
+
+// PNG draws the chart as a bitmap in pixels, e.g. to attach to a ticket. It
+// has no text, the wave is drawn between gridlines at Min, the middle and Max.
+type PNG struct{}
+
+// pngPadding keeps the wave clear of the edges of the image.
+const pngPadding = 4
+
+func (PNG) ContentType() string {
+	return "image/png"
+}
+
+func (PNG) Size() (int, int) {
+	return 720, 200
+}
+
+func (PNG) Render(w io.Writer, c Chart) error {
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	top, bottom := float64(pngPadding), float64(c.Height-1-pngPadding)
+	y := func(v float64) int {
+		return int(math.Round(c.scale(v, bottom, top)))
+	}
+
+	for _, v := range []float64{c.Min, (c.Min + c.Max) / 2, c.Max} {
+		line(img, 0, y(v), c.Width-1, y(v), gridColour)
+	}
+	if i := c.NowIndex(); i >= 0 {
+		line(img, i, 0, i, c.Height-1, nowColour)
+	}
+	for i := 1; i < len(c.Points); i++ {
+		line(img, i-1, y(c.Points[i-1].Value), i, y(c.Points[i].Value), waveColour)
+	}
+	if i := c.NowIndex(); i >= 0 {
+		dot(img, i, y(c.Points[i].Value), 3, nowColour)
+	}
+	return png.Encode(w, img)
+}
+
+// line draws from (x0, y0) to (x1, y1) with Bresenham's algorithm.
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func dot(img *image.RGBA, cx, cy, r int, c color.RGBA) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				img.SetRGBA(cx+x, cy+y, c)
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// JSON writes the chart as a time series in the shape of the Grafana JSON
+// datasource, with datapoints of [value, unix milliseconds].
+type JSON struct{}
+
+type series struct {
+	Target     string       `json:"target"`
+	From       int64        `json:"from"`
+	To         int64        `json:"to"`
+	Now        int64        `json:"now"`
+	Min        float64      `json:"min"`
+	Max        float64      `json:"max"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+func (JSON) ContentType() string {
+	return "application/json"
+}
+
+// Size is the default number of points, the height is unused.
+func (JSON) Size() (int, int) {
+	return 120, 2
+}
+
+func (JSON) Render(w io.Writer, c Chart) error {
+	s := series{
+		Target:     "trig",
+		From:       c.From.UnixMilli(),
+		To:         c.To.UnixMilli(),
+		Now:        c.Now.UnixMilli(),
+		Min:        c.Min,
+		Max:        c.Max,
+		Datapoints: make([][2]float64, len(c.Points)),
+	}
+	for i, p := range c.Points {
+		s.Datapoints[i] = [2]float64{p.Value, float64(p.Time.UnixMilli())}
+	}
+	return json.NewEncoder(w).Encode(s)
+}
+
+// scale maps v from the value axis onto from..to, clamped to it.
+func (c Chart) scale(v, from, to float64) float64 {
+	v = min(max(v, c.Min), c.Max)
+	return translate(v, c.Min, c.Max, from, to)
+}
@@ -2,8 +2,6 @@ package trig
 
 import (
 	"fmt"
-	"math"
-	"strconv"
 	"time"
 )
 
@@ -11,130 +9,115 @@ type Args struct {
 	Duration string
 	Min      int32
 	Max      int32
-	Current  int32
+	// Anchor is an instant a period starts at, the wave repeats every Duration
+	// before and after it. Unset anchors to midnight UTC on 1 January 1970, so
+	// periods which divide a day start at midnight.
+	Anchor time.Time
+	// Location is the timezone periods are counted in. Elapsed time follows
+	// its wall clock, so a daily wave stays aligned to local midnight across
+	// daylight saving changes. It defaults to UTC.
+	Location *time.Location
+	// Waveform is one of Waveforms, it defaults to Sine.
+	Waveform string
+	// Phase shifts the wave later through its period.
+	Phase time.Duration
+	// Amplitude is the fraction of the Min to Max range the wave spans around
+	// its middle. Unset spans the whole range.
+	Amplitude float64
+	// DutyCycle is the fraction of the period square and pulse waves spend
+	// high, it defaults to 0.5 for square and 0.1 for pulse.
+	DutyCycle float64
+	// Seed picks the noise wave.
+	Seed int64
 }
 
-func makeCanvas(cols, rows int) [][]string {
-	matrix := make([][]string, rows)
-	for i := range matrix {
-		matrix[i] = make([]string, cols)
-	}
-	return matrix
+// Wave is Args with the period parsed and the anchor resolved, to evaluate
+// many times over, e.g. when sampling a chart.
+type Wave struct {
+	Args
+	period time.Duration
+	loc    *time.Location
+	// anchor is the wall clock time of a.Anchor in loc.
+	anchor time.Time
 }
 
-func GetValue(a Args) (float64, error) {
-	angle, err := calculateAngle(a)
+// NewWave parses a.
+func NewWave(a Args) (Wave, error) {
+	d, err := time.ParseDuration(a.Duration)
 	if err != nil {
-		return 0, err
+		return Wave{}, err
 	}
-
-	t := translate(angle, -1, 1, float64(a.Min), float64(a.Max))
-	return t, nil
-}
-
-func RenderChart(a Args) string {
-	duration, err := time.ParseDuration(a.Duration)
-	if err != nil {
-		return "could not render chart"
+	if d <= 0 {
+		return Wave{}, fmt.Errorf("duration must be positive, got %s", d)
 	}
-	_, secondsSinceIncrement := getStart(duration)
-	dSeconds := duration.Seconds()
-	results := make([]float64, int(dSeconds))
-	var yMin, yMax float64
+	return newWave(a, d), nil
+}
 
-	for i := 1; i <= int(dSeconds)-1; i++ {
-		args := Args{
-			Duration: a.Duration,
-			Min:      a.Min,
-			Max:      a.Max,
-			Current:  int32(i),
-		}
-		value, err := GetValue(args)
-		if err != nil {
-			fmt.Println(err)
-			return "could not render chart"
-		}
-		results[i] = value
-		if value < yMin {
-			yMin = value
-		}
-		if value > yMax {
-			yMax = value
-		}
+func newWave(a Args, d time.Duration) Wave {
+	loc := location(a)
+	anchor := a.Anchor
+	if anchor.IsZero() {
+		anchor = time.Date(1970, 1, 1, 0, 0, 0, 0, loc)
 	}
+	return Wave{Args: a, period: d, loc: loc, anchor: wallClock(anchor, loc)}
+}
 
-	canvasAxisPadding := len(strconv.Itoa(int(a.Max))) + 1
-	canvasAxisOffset := 1
-	canvasWidth := 120 + canvasAxisOffset
-	canvasHeight := 12
-
-	canvas := makeCanvas(canvasWidth, canvasHeight)
-	translatedSeconds := int(translate(float64(secondsSinceIncrement), 0, dSeconds, 0, float64(canvasWidth-1)))
-	for row := range canvas {
-		for col := range canvas[row] {
-			if col < canvasAxisOffset {
-				nodeY := int(translate(float64(row), 0, float64(canvasHeight-1), float64(a.Min), float64(a.Max))) - 1
-				canvas[row][col] = fmt.Sprintf("%-*d", canvasAxisPadding, nodeY)
-				continue
-			}
+// Period is the parsed Duration.
+func (w Wave) Period() time.Duration {
+	return w.period
+}
 
-			if col == translatedSeconds+canvasAxisOffset {
-				canvas[row][col] = "|"
-			} else {
-				canvas[row][col] = " "
-			}
-		}
-	}
+// Value is the value of the wave at t, between Min and Max.
+func (w Wave) Value(t time.Time) float64 {
+	return translate(wave(w.Args, w.position(t))*amplitude(w.Args), -1, 1, float64(w.Min), float64(w.Max))
+}
 
-	sampledResults := make([]float64, canvasWidth-canvasAxisOffset)
-	for i := 0; i < len(sampledResults)-1; i++ {
-		transI := int(translate(float64(i), 0, float64(canvasWidth-1), 0, dSeconds-1))
-		sampledResults[i] = results[transI]
-	}
+// Start is when the period containing t began.
+func (w Wave) Start(t time.Time) time.Time {
+	s := wallClock(t, w.loc).Add(-w.sincePeriodStart(t))
+	return time.Date(s.Year(), s.Month(), s.Day(), s.Hour(), s.Minute(), s.Second(), s.Nanosecond(), w.loc)
+}
 
-	for i, value := range sampledResults {
-		constrainedY := int(translate(value, yMin, yMax, 0, float64(canvasHeight-1)))
-		if i == translatedSeconds {
-			canvas[constrainedY][i+canvasAxisOffset] = "#"
-		} else {
-			canvas[constrainedY][i+canvasAxisOffset] = "*"
-		}
-	}
+// position is how far through its period the wave is at t, from 0 up to 1.
+func (w Wave) position(t time.Time) float64 {
+	return float64(w.sincePeriodStart(t.Add(w.Phase))) / float64(w.period)
+}
 
-	// Print the canvas
-	var output string
-	for yI := len(canvas) - 1; yI >= 0; yI-- {
-		for x := range canvas[yI] {
-			output += fmt.Sprintf(canvas[yI][x])
-		}
-		output += fmt.Sprintf("\n")
+// sincePeriodStart is how far t is into its period, by the wall clock of
+// the wave's location.
+func (w Wave) sincePeriodStart(t time.Time) time.Duration {
+	since := wallClock(t, w.loc).Sub(w.anchor) % w.period
+	if since < 0 {
+		since += w.period
 	}
-	return output
+	return since
 }
 
-func calculateAngle(a Args) (float64, error) {
-	d, err := time.ParseDuration(a.Duration)
+// GetValue is the value of the wave at t, between Min and Max.
+func GetValue(a Args, t time.Time) (float64, error) {
+	w, err := NewWave(a)
 	if err != nil {
 		return 0, err
 	}
+	return w.Value(t), nil
+}
 
-	if a.Current == 0 {
-		_, secondsSinceIncrement := getStart(d)
-		a.Current = int32(secondsSinceIncrement)
-	}
+// Start is when the period of length d containing t began.
+func Start(a Args, d time.Duration, t time.Time) time.Time {
+	return newWave(a, d).Start(t)
+}
 
-	angle := (2 * math.Pi * (float64(a.Current))) / d.Seconds()
-	return math.Sin(angle), nil
+func location(a Args) *time.Location {
+	if a.Location == nil {
+		return time.UTC
+	}
+	return a.Location
 }
 
-func getStart(d time.Duration) (time.Time, int) {
-	now := time.Now().UTC()
-	elapsedSeconds := int(now.Unix() % 86400)
-	startOfDay := now.Add(-time.Duration(elapsedSeconds) * time.Second)
-	numDurations := int(elapsedSeconds) / int(d.Seconds())
-	mostRecentIncrement := startOfDay.Add(time.Duration(numDurations) * d)
-	secondsSinceIncrement := int(now.Sub(mostRecentIncrement).Seconds())
-	return mostRecentIncrement, secondsSinceIncrement
+// wallClock is the local time at t in loc, as though loc were UTC.
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), l.Hour(), l.Minute(), l.Second(), l.Nanosecond(), time.UTC)
 }
 
 func translate(x, inMin, inMax, outMin, outMax float64) float64 {
@@ -0,0 +1,116 @@
+package trig
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone %s unavailable: %v", name, err)
+	}
+	return loc
+}
+
+func TestStartAlignsToMidnightByDefault(t *testing.T) {
+	a := Args{Duration: "5m"}
+	at := time.Date(2025, 5, 1, 12, 3, 20, 0, time.UTC)
+	want := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	if got := Start(a, 5*time.Minute, at); !got.Equal(want) {
+		t.Errorf("Start() = %s, want %s", got, want)
+	}
+}
+
+func TestPeriodsWhichDoNotDivideADayCarryOverMidnight(t *testing.T) {
+	a := Args{Duration: "7m", Min: 0, Max: 100}
+	d := 7 * time.Minute
+	midnight := time.Date(2025, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	// 2025-05-02 is 20210 days after the epoch, which is not a whole number
+	// of 7m periods, so midnight falls part way through one.
+	if got := Start(a, d, midnight); got.Equal(midnight) {
+		t.Errorf("Start() restarted the wave at midnight")
+	}
+	before, _ := GetValue(a, midnight.Add(-time.Second))
+	after, _ := GetValue(a, midnight.Add(time.Second))
+	// The steepest a 7m sine between 0 and 100 moves in 2s is ~1.5.
+	if math.Abs(after-before) > 1.5 {
+		t.Errorf("wave jumped from %.2f to %.2f across midnight", before, after)
+	}
+}
+
+func TestWeeklyPeriodAnchoredInTimezone(t *testing.T) {
+	melbourne := mustLoad(t, "Australia/Melbourne")
+	a, err := FromSpec(shared.TrigMode{
+		Duration: "168h",
+		Min:      2,
+		Max:      10,
+		Anchor:   "2025-01-06T09:00:00",
+		Timezone: "Australia/Melbourne",
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 6, 9, 0, 0, 0, melbourne); !a.Anchor.Equal(want) {
+		t.Fatalf("Anchor = %s, want %s", a.Anchor, want)
+	}
+
+	cases := []struct {
+		at   time.Time
+		want float64
+	}{
+		{time.Date(2025, 1, 6, 9, 0, 0, 0, melbourne), 6},
+		{time.Date(2025, 1, 8, 3, 0, 0, 0, melbourne), 10},
+		{time.Date(2025, 1, 9, 21, 0, 0, 0, melbourne), 6},
+		{time.Date(2025, 1, 11, 15, 0, 0, 0, melbourne), 2},
+		{time.Date(2025, 3, 3, 9, 0, 0, 0, melbourne), 6},
+	}
+	for _, tc := range cases {
+		got, err := GetValue(a, tc.at)
+		if err != nil {
+			t.Fatalf("GetValue() error = %v", err)
+		}
+		if math.Abs(got-tc.want) > 1e-6 {
+			t.Errorf("GetValue(%s) = %v, want %v", tc.at.Format(time.RFC1123), got, tc.want)
+		}
+	}
+}
+
+func TestDailyPeriodFollowsLocalMidnightAcrossDaylightSaving(t *testing.T) {
+	melbourne := mustLoad(t, "Australia/Melbourne")
+	a := Args{Duration: "24h", Location: melbourne}
+
+	// Daylight saving ended at 3am on 6 April 2025, making that day 25h long.
+	for _, day := range []int{5, 6, 7} {
+		at := time.Date(2025, 4, day, 18, 0, 0, 0, melbourne)
+		want := time.Date(2025, 4, day, 0, 0, 0, 0, melbourne)
+		if got := Start(a, 24*time.Hour, at); !got.Equal(want) {
+			t.Errorf("Start(%s) = %s, want %s", at.Format(time.RFC1123), got.In(melbourne).Format(time.RFC1123), want.Format(time.RFC1123))
+		}
+	}
+}
+
+func TestFromSpecRejectsBadAnchors(t *testing.T) {
+	invalid := []shared.TrigMode{
+		{Anchor: "last monday"},
+		{Timezone: "Mars/Olympus_Mons"},
+	}
+	for _, spec := range invalid {
+		if _, err := FromSpec(spec); err == nil {
+			t.Errorf("FromSpec(%+v) expected an error", spec)
+		}
+	}
+
+	a, err := FromSpec(shared.TrigMode{Anchor: "2025-01-06T09:00:00+11:00", Timezone: "UTC"})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	if want := time.Date(2025, 1, 5, 22, 0, 0, 0, time.UTC); !a.Anchor.Equal(want) {
+		t.Errorf("an anchor with an offset = %s, want %s", a.Anchor, want)
+	}
+}
@@ -0,0 +1,215 @@
+package trig
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+// Waveforms the wave can take. Each starts its period at the same point a sine
+// does, at the middle of the range heading up, except cosine which starts at
+// the top, square and pulse which start high, sawtooth which ramps up from the
+// bottom to drop back at the end of the period, and noise which starts wherever
+// its seed puts it.
+const (
+	Sine     = "sine"
+	Cosine   = "cosine"
+	Square   = "square"
+	Triangle = "triangle"
+	Sawtooth = "sawtooth"
+	Pulse    = "pulse"
+	Noise    = "noise"
+)
+
+// Default duty cycles, the fraction of the period square and pulse waves spend
+// high.
+const (
+	defaultSquareDuty = 0.5
+	defaultPulseDuty  = 0.1
+)
+
+// noiseKnots is how many random points noise interpolates between each period.
+const noiseKnots = 8
+
+var waveforms = []string{Sine, Cosine, Square, Triangle, Sawtooth, Pulse, Noise}
+
+// Waveforms lists the supported waveforms.
+func Waveforms() []string {
+	return slices.Clone(waveforms)
+}
+
+// FromSpec converts the trig block of a Ship into Args.
+func FromSpec(t shared.TrigMode) (Args, error) {
+	a := Args{
+		Duration: t.Duration,
+		Min:      t.Min,
+		Max:      t.Max,
+		Waveform: t.Waveform,
+		Seed:     t.Seed,
+	}
+	if a.Waveform != "" && !slices.Contains(waveforms, a.Waveform) {
+		return a, fmt.Errorf("trig.waveform: unknown waveform %q", a.Waveform)
+	}
+	if t.Phase != "" {
+		phase, err := time.ParseDuration(t.Phase)
+		if err != nil {
+			return a, fmt.Errorf("trig.phase: %w", err)
+		}
+		a.Phase = phase
+	}
+	if t.Amplitude != "" {
+		amp, err := strconv.ParseFloat(t.Amplitude, 64)
+		if err != nil || amp <= 0 || amp > 1 {
+			return a, fmt.Errorf("trig.amplitude: must be a number above 0 and at most 1, got %q", t.Amplitude)
+		}
+		a.Amplitude = amp
+	}
+	if t.Timezone != "" {
+		loc, err := time.LoadLocation(t.Timezone)
+		if err != nil {
+			return a, fmt.Errorf("trig.timezone: %w", err)
+		}
+		a.Location = loc
+	}
+	if t.Anchor != "" {
+		anchor, err := parseAnchor(t.Anchor, a.Location)
+		if err != nil {
+			return a, fmt.Errorf("trig.anchor: %w", err)
+		}
+		a.Anchor = anchor
+	}
+	if t.DutyCycle != 0 {
+		if t.DutyCycle < 1 || t.DutyCycle > 99 {
+			return a, fmt.Errorf("trig.dutyCycle: must be between 1 and 99, got %d", t.DutyCycle)
+		}
+		a.DutyCycle = float64(t.DutyCycle) / 100
+	}
+	return a, nil
+}
+
+// anchorLayouts are tried in order. Layouts without an offset are read in the
+// trig timezone.
+var anchorLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+func parseAnchor(s string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range anchorLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("must be an RFC 3339 timestamp or a date, e.g. 2025-01-06T09:00:00, got %q", s)
+}
+
+// wave is the value of the waveform, between -1 and 1, at position p through
+// its period, where 0 <= p < 1.
+func wave(a Args, p float64) float64 {
+	switch a.Waveform {
+	case Cosine:
+		return math.Cos(2 * math.Pi * p)
+	case Square:
+		return high(p < duty(a))
+	case Pulse:
+		return high(p < duty(a))
+	case Triangle:
+		switch {
+		case p < 0.25:
+			return 4 * p
+		case p < 0.75:
+			return 2 - 4*p
+		default:
+			return 4*p - 4
+		}
+	case Sawtooth:
+		return 2*p - 1
+	case Noise:
+		return noise(a.Seed, p)
+	}
+	return math.Sin(2 * math.Pi * p)
+}
+
+func duty(a Args) float64 {
+	if a.DutyCycle > 0 {
+		return a.DutyCycle
+	}
+	if a.Waveform == Pulse {
+		return defaultPulseDuty
+	}
+	return defaultSquareDuty
+}
+
+func high(b bool) float64 {
+	if b {
+		return 1
+	}
+	return -1
+}
+
+// noise smoothly interpolates between noiseKnots random points which wrap
+// around the period, so it repeats every period and every reader with the same
+// seed sees the same wave.
+func noise(seed int64, p float64) float64 {
+	x := p * noiseKnots
+	i := int(math.Floor(x))
+	f := x - float64(i)
+	smooth := (1 - math.Cos(math.Pi*f)) / 2
+	from := knot(seed, i%noiseKnots)
+	to := knot(seed, (i+1)%noiseKnots)
+	return from + (to-from)*smooth
+}
+
+// knot is a pseudo-random value between -1 and 1, derived from seed and i with
+// splitmix64 so no generator state is kept.
+func knot(seed int64, i int) float64 {
+	z := uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	return float64(z>>11)/float64(1<<53)*2 - 1
+}
+
+// amplitude scales the wave around the middle of the range, 1 spans it fully.
+func amplitude(a Args) float64 {
+	if a.Amplitude > 0 {
+		return a.Amplitude
+	}
+	return 1
+}
+
+// Step is how long the value can take to move by one whole replica from t.
+// Smooth waves use their steepest slope, square and pulse waves the time until
+// their next edge. A flat wave returns 0.
+func Step(a Args, t time.Time) (time.Duration, error) {
+	w, err := NewWave(a)
+	if err != nil {
+		return 0, err
+	}
+	d := w.Period()
+	span := float64(a.Max-a.Min) * amplitude(a)
+	if span <= 0 {
+		return 0, nil
+	}
+
+	p := w.position(t)
+	untilWrap := time.Duration((1 - p) * float64(d))
+	switch a.Waveform {
+	case Square, Pulse:
+		if on := duty(a); p < on {
+			return time.Duration((on - p) * float64(d)), nil
+		}
+		return untilWrap, nil
+	case Triangle:
+		return time.Duration(float64(d) / (2 * span)), nil
+	case Sawtooth:
+		return min(time.Duration(float64(d)/span), untilWrap), nil
+	case Noise:
+		return time.Duration(float64(d) / (4 * math.Pi * span)), nil
+	}
+	return time.Duration(float64(d) / (math.Pi * span)), nil
+}
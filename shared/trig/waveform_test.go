@@ -0,0 +1,148 @@
+package trig
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+)
+
+func TestWave(t *testing.T) {
+	cases := []struct {
+		waveform string
+		duty     float64
+		want     map[float64]float64
+	}{
+		{Sine, 0, map[float64]float64{0: 0, 0.25: 1, 0.5: 0, 0.75: -1}},
+		{Cosine, 0, map[float64]float64{0: 1, 0.25: 0, 0.5: -1, 0.75: 0}},
+		{Triangle, 0, map[float64]float64{0: 0, 0.125: 0.5, 0.25: 1, 0.5: 0, 0.75: -1, 0.875: -0.5}},
+		{Sawtooth, 0, map[float64]float64{0: -1, 0.25: -0.5, 0.5: 0, 0.99: 0.98}},
+		{Square, 0, map[float64]float64{0: 1, 0.49: 1, 0.5: -1, 0.99: -1}},
+		{Square, 0.25, map[float64]float64{0.2: 1, 0.3: -1}},
+		{Pulse, 0, map[float64]float64{0: 1, 0.09: 1, 0.1: -1, 0.5: -1}},
+	}
+	for _, tc := range cases {
+		a := Args{Waveform: tc.waveform, DutyCycle: tc.duty}
+		for p, want := range tc.want {
+			if got := wave(a, p); math.Abs(got-want) > 1e-9 {
+				t.Errorf("%s(%v) = %v, want %v", tc.waveform, p, got, want)
+			}
+		}
+	}
+}
+
+// TestWaveStarts pins where each waveform starts its period, as documented on
+// the waveform constants.
+func TestWaveStarts(t *testing.T) {
+	starts := map[string]float64{Sine: 0, Triangle: 0, Cosine: 1, Square: 1, Pulse: 1, Sawtooth: -1}
+	for waveform, want := range starts {
+		if got := wave(Args{Waveform: waveform}, 0); got != want {
+			t.Errorf("%s starts at %v, want %v", waveform, got, want)
+		}
+	}
+}
+
+func TestNoiseIsSeededAndBounded(t *testing.T) {
+	a := Args{Waveform: Noise, Seed: 42}
+	b := Args{Waveform: Noise, Seed: 7}
+	differs := false
+	for i := 0; i < 1000; i++ {
+		p := float64(i) / 1000
+		v := wave(a, p)
+		if v < -1 || v > 1 {
+			t.Fatalf("noise(%v) = %v, outside -1..1", p, v)
+		}
+		if v != wave(a, p) {
+			t.Fatalf("noise(%v) is not deterministic", p)
+		}
+		if v != wave(b, p) {
+			differs = true
+		}
+	}
+	if !differs {
+		t.Error("noise with different seeds produced the same wave")
+	}
+	if start, end := wave(a, 0), wave(a, 0.999999); math.Abs(start-end) > 1e-3 {
+		t.Errorf("noise does not wrap around its period: %v at the start, %v at the end", start, end)
+	}
+}
+
+func TestGetValueAmplitudeAndPhase(t *testing.T) {
+	a := Args{Duration: "100s", Min: 0, Max: 10}
+	peak := time.Unix(25, 0)
+	if got, _ := GetValue(a, peak); got != 10 {
+		t.Errorf("GetValue() at the peak = %v, want 10", got)
+	}
+
+	a.Amplitude = 0.5
+	if got, _ := GetValue(a, peak); got != 7.5 {
+		t.Errorf("GetValue() at the peak with amplitude 0.5 = %v, want 7.5", got)
+	}
+
+	a.Amplitude = 0
+	a.Phase = 50 * time.Second
+	if got, _ := GetValue(a, peak); math.Abs(got) > 1e-9 {
+		t.Errorf("GetValue() half a period out of phase = %v, want 0", got)
+	}
+}
+
+func TestFromSpec(t *testing.T) {
+	a, err := FromSpec(shared.TrigMode{
+		Duration:  "10m",
+		Min:       1,
+		Max:       9,
+		Waveform:  Square,
+		Phase:     "-90s",
+		Amplitude: "0.8",
+		DutyCycle: 30,
+		Seed:      3,
+	})
+	if err != nil {
+		t.Fatalf("FromSpec() error = %v", err)
+	}
+	want := Args{Duration: "10m", Min: 1, Max: 9, Waveform: Square, Phase: -90 * time.Second, Amplitude: 0.8, DutyCycle: 0.3, Seed: 3}
+	if a != want {
+		t.Errorf("FromSpec() = %+v, want %+v", a, want)
+	}
+
+	invalid := []shared.TrigMode{
+		{Waveform: "zigzag"},
+		{Phase: "soon"},
+		{Amplitude: "1.5"},
+		{Amplitude: "0"},
+		{DutyCycle: 100},
+	}
+	for _, spec := range invalid {
+		if _, err := FromSpec(spec); err == nil {
+			t.Errorf("FromSpec(%+v) expected an error", spec)
+		}
+	}
+}
+
+func TestStep(t *testing.T) {
+	cases := []struct {
+		name string
+		args Args
+		at   int64
+		want time.Duration
+	}{
+		{"sine", Args{Duration: "300s", Min: 1, Max: 6}, 10, 19098593171 * time.Nanosecond},
+		{"triangle", Args{Duration: "100s", Min: 0, Max: 10, Waveform: Triangle}, 10, 5 * time.Second},
+		{"square until its falling edge", Args{Duration: "100s", Min: 0, Max: 10, Waveform: Square}, 10, 40 * time.Second},
+		{"square until its rising edge", Args{Duration: "100s", Min: 0, Max: 10, Waveform: Square}, 70, 30 * time.Second},
+		{"sawtooth until it wraps", Args{Duration: "100s", Min: 0, Max: 1, Waveform: Sawtooth}, 90, 10 * time.Second},
+		{"flat", Args{Duration: "100s", Min: 4, Max: 4}, 0, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Step(tc.args, time.Unix(tc.at, 0))
+			if err != nil {
+				t.Fatalf("Step() error = %v", err)
+			}
+			if (got - tc.want).Abs() > time.Millisecond {
+				t.Errorf("Step() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
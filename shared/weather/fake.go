@@ -0,0 +1,169 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/socialviolation/freyr/shared/clock"
+)
+
+// Script is what a Fake provider reports, e.g.
+//
+//	{"interval": "5m", "steps": [{"temp": 8}, {"temp": 16}, {"temp": 31}]}
+//
+// Steps cycle every interval, counted from the Unix epoch so every reader sees
+// the same step at the same time. Without an interval the first step holds.
+type Script struct {
+	Interval string       `json:"interval,omitempty"`
+	Steps    []Conditions `json:"steps"`
+}
+
+// At is the step active at t.
+func (s Script) At(t time.Time) (Conditions, error) {
+	if len(s.Steps) == 0 {
+		return Conditions{}, errors.New("script has no steps")
+	}
+	if s.Interval == "" {
+		return s.Steps[0], nil
+	}
+	interval, err := time.ParseDuration(s.Interval)
+	if err != nil {
+		return Conditions{}, fmt.Errorf("script interval: %w", err)
+	}
+	if interval <= 0 {
+		return Conditions{}, fmt.Errorf("script interval: must be positive, got %s", interval)
+	}
+	step := (t.UnixNano() / int64(interval)) % int64(len(s.Steps))
+	if step < 0 {
+		step += int64(len(s.Steps))
+	}
+	return s.Steps[step], nil
+}
+
+// Fake reports scripted conditions for every location, for demos and tests
+// which can't reach a real weather API. The script is re-read on every call
+// so it can be edited while a Ship runs.
+type Fake struct {
+	// Source is an http(s):// URL serving a Script as JSON, or a file path or
+	// file:// URL within Dir.
+	Source string
+	// Dir is the only directory file sources are read from, they're refused
+	// without one.
+	Dir string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Clock picks the step, it defaults to clock.Real.
+	Clock clock.Clock
+}
+
+var (
+	_ Provider   = Fake{}
+	_ Forecaster = Fake{}
+)
+
+// maxScriptReadings bounds a scripted forecast.
+const maxScriptReadings = 10_000
+
+func (f Fake) Conditions(ctx context.Context, _ Location) (Conditions, error) {
+	script, err := f.load(ctx)
+	if err != nil {
+		return Conditions{}, fmt.Errorf("fake weather %s: %w", f.Source, err)
+	}
+	clk := f.Clock
+	if clk == nil {
+		clk = clock.Real{}
+	}
+	c, err := script.At(clk.Now())
+	if err != nil {
+		return Conditions{}, fmt.Errorf("fake weather %s: %w", f.Source, err)
+	}
+	return c, nil
+}
+
+// Forecast replays the script, which is as predictable as it gets, with a
+// reading at every step from the one active at from.
+func (f Fake) Forecast(ctx context.Context, _ Location, from, to time.Time) ([]Reading, error) {
+	script, err := f.load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fake weather %s: %w", f.Source, err)
+	}
+	return script.Forecast(from, to)
+}
+
+// Forecast is a reading at the start of every step from the one active at from
+// until to.
+func (s Script) Forecast(from, to time.Time) ([]Reading, error) {
+	c, err := s.At(from)
+	if err != nil {
+		return nil, err
+	}
+	if s.Interval == "" {
+		return []Reading{{At: from, Conditions: c}}, nil
+	}
+	interval, _ := time.ParseDuration(s.Interval)
+	start := time.Unix(0, from.UnixNano()/int64(interval)*int64(interval)).In(from.Location())
+	if start.After(from) {
+		start = start.Add(-interval)
+	}
+	var readings []Reading
+	for at := start; !at.After(to) && len(readings) < maxScriptReadings; at = at.Add(interval) {
+		c, _ := s.At(at)
+		readings = append(readings, Reading{At: at, Conditions: c})
+	}
+	return readings, nil
+}
+
+func (f Fake) load(ctx context.Context) (Script, error) {
+	var r io.Reader
+	switch {
+	case strings.HasPrefix(f.Source, "http://"), strings.HasPrefix(f.Source, "https://"):
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Source, nil)
+		if err != nil {
+			return Script{}, err
+		}
+		hc := f.HTTPClient
+		if hc == nil {
+			hc = http.DefaultClient
+		}
+		resp, err := hc.Do(req)
+		if err != nil {
+			return Script{}, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return Script{}, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		r = resp.Body
+	default:
+		if f.Dir == "" {
+			return Script{}, errors.New("file sources are disabled, use an http(s) URL")
+		}
+		name := strings.TrimPrefix(f.Source, "file://")
+		if filepath.IsAbs(name) {
+			rel, err := filepath.Rel(f.Dir, name)
+			if err != nil {
+				return Script{}, err
+			}
+			name = rel
+		}
+		// OpenInRoot refuses paths and symlinks leading out of Dir.
+		file, err := os.OpenInRoot(f.Dir, name)
+		if err != nil {
+			return Script{}, err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	var s Script
+	err := json.NewDecoder(r).Decode(&s)
+	return s, err
+}
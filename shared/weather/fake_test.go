@@ -0,0 +1,132 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+const script = `{"interval": "5m", "steps": [{"temp": 8}, {"temp": 16, "humidity": 40}, {"temp": 31}]}`
+
+func TestScriptAt(t *testing.T) {
+	s := Script{Interval: "5m", Steps: []Conditions{{Temp: 8}, {Temp: 16}, {Temp: 31}}}
+	midnight := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		after time.Duration
+		want  float64
+	}{
+		{0, 8},
+		{4*time.Minute + 59*time.Second, 8},
+		{5 * time.Minute, 16},
+		{10 * time.Minute, 31},
+		{15 * time.Minute, 8},
+	}
+	for _, tc := range cases {
+		got, err := s.At(midnight.Add(tc.after))
+		if err != nil {
+			t.Fatalf("At(+%s) error = %v", tc.after, err)
+		}
+		if got.Temp != tc.want {
+			t.Errorf("At(+%s).Temp = %v, want %v", tc.after, got.Temp, tc.want)
+		}
+	}
+
+	held := Script{Steps: []Conditions{{Temp: 21}, {Temp: 30}}}
+	if got, _ := held.At(midnight.Add(time.Hour)); got.Temp != 21 {
+		t.Errorf("At() without an interval = %v, want the first step", got.Temp)
+	}
+	if _, err := (Script{}).At(midnight); err == nil {
+		t.Error("At() on an empty script expected an error")
+	}
+}
+
+func TestFakeSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "weather.json")
+	if err := os.WriteFile(path, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, script)
+	}))
+	defer srv.Close()
+
+	// 00:05 is the second step.
+	clk := fixedClock(time.Date(2025, 5, 1, 0, 5, 0, 0, time.UTC))
+	for _, source := range []string{path, "file://" + path, "weather.json", srv.URL} {
+		f := Fake{Source: source, Dir: dir, Clock: clk}
+		got, err := f.Conditions(context.Background(), Location{City: "Melbourne", Country: "AU"})
+		if err != nil {
+			t.Fatalf("Conditions() from %s error = %v", source, err)
+		}
+		if got != (Conditions{Temp: 16, Humidity: 40}) {
+			t.Errorf("Conditions() from %s = %+v", source, got)
+		}
+	}
+
+	_, err := Fake{Source: filepath.Join(dir, "missing.json"), Dir: dir}.Conditions(context.Background(), Location{})
+	if err == nil {
+		t.Error("Conditions() from a missing file expected an error")
+	}
+
+	// Files are only read from within Dir, and not at all without one.
+	secret := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(secret, []byte(script), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rel, _ := filepath.Rel(dir, secret)
+	for _, f := range []Fake{
+		{Source: path},
+		{Source: secret, Dir: dir},
+		{Source: rel, Dir: dir},
+	} {
+		if _, err := f.Conditions(context.Background(), Location{}); err == nil {
+			t.Errorf("Conditions() from %s in %q expected an error", f.Source, f.Dir)
+		}
+	}
+}
+
+func TestScriptForecast(t *testing.T) {
+	s := Script{Interval: "5m", Steps: []Conditions{{Temp: 8}, {Temp: 16}, {Temp: 31}}}
+	from := time.Date(2025, 5, 1, 0, 7, 0, 0, time.UTC)
+	readings, err := s.Forecast(from, from.Add(10*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Reading{
+		{At: from.Add(-2 * time.Minute), Conditions: Conditions{Temp: 16}},
+		{At: from.Add(3 * time.Minute), Conditions: Conditions{Temp: 31}},
+		{At: from.Add(8 * time.Minute), Conditions: Conditions{Temp: 8}},
+	}
+	if fmt.Sprint(readings) != fmt.Sprint(want) {
+		t.Errorf("Forecast() = %v, want %v", readings, want)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	start := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)
+	var readings []Reading
+	for i := 0; i < 8; i++ {
+		readings = append(readings, Reading{At: start.Add(time.Duration(i) * 3 * time.Hour)})
+	}
+
+	got := Window(readings, start.Add(4*time.Hour), start.Add(9*time.Hour))
+	if len(got) != 3 || !got[0].At.Equal(start.Add(3*time.Hour)) || !got[2].At.Equal(start.Add(9*time.Hour)) {
+		t.Errorf("Window() = %v, want the readings from 03:00 to 09:00", got)
+	}
+	if got := Window(readings, start.Add(-time.Hour), start.Add(time.Hour)); len(got) != 1 || !got[0].At.Equal(start) {
+		t.Errorf("Window() before the first reading = %v, want the first reading", got)
+	}
+}
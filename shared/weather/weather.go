@@ -0,0 +1,58 @@
+// Package weather describes the current conditions weather mode scales on and
+// the providers which report them.
+package weather
+
+import (
+	"context"
+	"time"
+)
+
+type Location struct {
+	Country string `json:"country"`
+	City    string `json:"city"`
+}
+
+// Conditions are the current weather readings a Ship can scale on, in metric
+// units with wind speed in m/s and humidity and cloud cover in percent.
+type Conditions struct {
+	Temp      float64 `json:"temp"`
+	FeelsLike float64 `json:"feels_like"`
+	Humidity  float64 `json:"humidity"`
+	WindSpeed float64 `json:"wind_speed"`
+	Clouds    float64 `json:"clouds"`
+}
+
+// Provider reports the current conditions at a location.
+type Provider interface {
+	Conditions(ctx context.Context, l Location) (Conditions, error)
+}
+
+// Reading is the conditions forecast from At until the next reading.
+type Reading struct {
+	At time.Time `json:"at"`
+	Conditions
+}
+
+// Forecaster is implemented by providers which can forecast the conditions at
+// a location. Forecast returns readings in time order covering as much of from
+// to to as the provider forecasts, see Window.
+type Forecaster interface {
+	Forecast(ctx context.Context, l Location, from, to time.Time) ([]Reading, error)
+}
+
+// Window trims time ordered readings to those covering from to to: the last
+// reading at or before from and every one after it up to to.
+func Window(readings []Reading, from, to time.Time) []Reading {
+	start := 0
+	for i, r := range readings {
+		if r.At.After(from) {
+			break
+		}
+		start = i
+	}
+	end := start
+	for end < len(readings) && !readings[end].At.After(to) {
+		end++
+	}
+	return readings[start:end]
+}
@@ -17,34 +17,77 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// Images used for the captain and conscript when the Ship does not set one.
+const (
+	DefaultCaptainImage   = "australia-southeast2-docker.pkg.dev/freyr-operator/imgs/captain:latest"
+	DefaultConscriptImage = "australia-southeast2-docker.pkg.dev/freyr-operator/imgs/conscript:latest"
+)
+
 // ShipSpec defines the desired state of Ship
 type ShipSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=weather;trig
-	// +kubebuilder:validation:default:=weather
+	// Mode selects the scaling mode used to compute the conscript target. Any
+	// mode registered with the shared scaling package is accepted, e.g. weather
+	// or trig. When unset the defaulting webhook picks weather if a city is
+	// configured and trig otherwise.
+	// +kubebuilder:validation:Optional
 	Mode string `json:"mode,omitempty"`
+	// Replicas is the conscript count targeted by manual mode. It is exposed
+	// through the scale subresource for `kubectl scale` and autoscalers.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+	// Scaling constrains the target computed by any mode before it is applied.
+	// +kubebuilder:validation:Optional
+	Scaling ScalingPolicy `json:"scaling,omitempty"`
 	// +kubebuilder:validation:Optional
 	Weather WeatherMode `json:"weather,omitempty"`
 	// +kubebuilder:validation:Optional
 	Trig TrigMode `json:"trig,omitempty"`
 	// +kubebuilder:validation:Optional
+	Schedule ScheduleMode `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Optional
+	Prometheus PrometheusMode `json:"prometheus,omitempty"`
+	// +kubebuilder:validation:Optional
+	External ExternalMode `json:"external,omitempty"`
+	// +kubebuilder:validation:Optional
+	Composite CompositeMode `json:"composite,omitempty"`
+	// +kubebuilder:validation:Optional
+	Replay ReplayMode `json:"replay,omitempty"`
+	// +kubebuilder:validation:Optional
 	Captain PodSpec `json:"captain,omitempty"`
 	// +kubebuilder:validation:Optional
 	Conscript PodSpec `json:"conscript,omitempty"`
+	// DeletionPolicy decides what happens to the Deployments, Service and
+	// ConfigMap when the Ship is deleted. Delete scales the conscripts down
+	// and drains the captain before removing them, Orphan leaves them running
+	// without owner references.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=Delete;Orphan
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 
 	// +kubebuilder:validation:Optional
 	EnvVars map[string]string `json:"envs"`
 }
 
+// DeletionPolicy is what happens to the resources owned by a deleted Ship.
+type DeletionPolicy string
+
+const (
+	DeletionPolicyDelete DeletionPolicy = "Delete"
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
 type PodSpec struct {
 	// +kubebuilder:validation:Optional
 	Image string `json:"image,omitempty"`
@@ -52,29 +95,434 @@ type PodSpec struct {
 	EnvVars map[string]string `json:"envs"`
 }
 
+// ScalingPolicy is applied to the target of every mode, in order: stabilization,
+// step limits, then the global bounds.
+type ScalingPolicy struct {
+	// MinReplicas is the lowest conscript count the operator will apply.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the highest conscript count the operator will apply.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+	// FallbackReplicas is targeted while the mode fails to evaluate. When unset
+	// the current conscript count is held.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	FallbackReplicas *int32 `json:"fallbackReplicas,omitempty"`
+	// LeadTime is how far ahead conscripts are added, e.g. 2m to cover the
+	// time they take to start and enlist. Scale ups are taken from the mode's
+	// forecast at now+leadTime, scale downs still happen at now. Modes which
+	// can't forecast ignore it.
+	// +kubebuilder:validation:Optional
+	LeadTime string `json:"leadTime,omitempty"`
+	// ScaleUp limits how quickly conscripts are added.
+	// +kubebuilder:validation:Optional
+	ScaleUp ScalingRules `json:"scaleUp,omitempty"`
+	// ScaleDown limits how quickly conscripts are removed.
+	// +kubebuilder:validation:Optional
+	ScaleDown ScalingRules `json:"scaleDown,omitempty"`
+}
+
+// ScalingRules limit scaling in one direction, like the HorizontalPodAutoscaler
+// behavior API.
+type ScalingRules struct {
+	// MaxStep is the most replicas that may be added or removed per StepPeriod.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MaxStep *int32 `json:"maxStep,omitempty"`
+	// StepPeriod is the interval MaxStep applies to, e.g. 60s. Defaults to 1m.
+	// +kubebuilder:validation:Optional
+	StepPeriod string `json:"stepPeriod,omitempty"`
+	// StabilizationWindow is how long past recommendations are considered
+	// before scaling in this direction, e.g. 5m.
+	// +kubebuilder:validation:Optional
+	StabilizationWindow string `json:"stabilizationWindow,omitempty"`
+}
+
 type WeatherMode struct {
-	// +kubebuilder:validation:Required
+	// Provider reports the weather. openweather needs an API key, openmeteo
+	// doesn't, and fake serves a scripted series from url for demos and tests.
+	// Defaults to openweather.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=openweather;openmeteo;fake
+	Provider string `json:"provider,omitempty"`
+	// URL overrides the openmeteo API, e.g. a self-hosted instance. For the
+	// fake provider it is the http(s) URL or file path of the script.
+	// +kubebuilder:validation:Optional
+	URL string `json:"url,omitempty"`
+	// GeocodingURL overrides the openmeteo geocoding API, which a self-hosted
+	// instance of the forecast API doesn't serve.
+	// +kubebuilder:validation:Optional
+	GeocodingURL string `json:"geocodingURL,omitempty"`
+	// Country is required by the openweather and openmeteo providers.
+	// +kubebuilder:validation:Optional
 	Country string `json:"country,omitempty"`
-	// +kubebuilder:validation:Required
+	// City is required by the openweather and openmeteo providers.
+	// +kubebuilder:validation:Optional
 	City string `json:"city,omitempty"`
-	// +kubebuilder:validation:Required
+	// APIKey is the OpenWeather API key.
+	// Deprecated: use APIKeySecretRef, an inline key is readable by anyone who
+	// can read the Ship.
+	// +kubebuilder:validation:Optional
 	APIKey string `json:"apiKey,omitempty"`
+	// APIKeySecretRef selects the key of a Secret in the Ship's namespace which
+	// holds the OpenWeather API key.
+	// +kubebuilder:validation:Optional
+	APIKeySecretRef *corev1.SecretKeySelector `json:"apiKeySecretRef,omitempty"`
+	// PollInterval is how often the current weather is re-read, e.g. 10m.
+	// Defaults to 5m and may not be shorter than 30s.
+	// +kubebuilder:validation:Optional
+	PollInterval string `json:"pollInterval,omitempty"`
+	// Signal is the reading the replica count follows. Defaults to temp.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=temp;feels_like;humidity;wind;clouds
+	Signal string `json:"signal,omitempty"`
+	// Mapping turns the signal into a replica count. Defaults to direct, which
+	// uses the signal as the count.
+	// +kubebuilder:validation:Optional
+	Mapping WeatherMapping `json:"mapping,omitempty"`
+}
+
+// WeatherMapping turns a weather signal into a replica count.
+type WeatherMapping struct {
+	// Type is direct, linear, inverse or thresholds. Linear scales
+	// inputMin..inputMax onto outputMin..outputMax, inverse onto
+	// outputMax..outputMin, and thresholds steps through a table.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=direct;linear;inverse;thresholds
+	Type string `json:"type,omitempty"`
+	// InputMin is the lower bound of the signal as a decimal string, e.g. "-5".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?$`
+	InputMin string `json:"inputMin,omitempty"`
+	// InputMax is the upper bound of the signal as a decimal string, e.g. "35".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?$`
+	InputMax string `json:"inputMax,omitempty"`
+	// OutputMin is also the target below the first threshold.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	OutputMin int32 `json:"outputMin,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	OutputMax int32 `json:"outputMax,omitempty"`
+	// Thresholds in ascending order. The last one the signal has reached sets
+	// the target.
+	// +kubebuilder:validation:Optional
+	Thresholds []WeatherThreshold `json:"thresholds,omitempty"`
+}
+
+// WeatherThreshold targets Replicas once the signal reaches Above.
+type WeatherThreshold struct {
+	// Above is a decimal string, e.g. "25.5".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^-?[0-9]+(\.[0-9]+)?$`
+	Above string `json:"above"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
 }
 
 type TrigMode struct {
 	Duration string `json:"duration,omitempty"`
 	Min      int32  `json:"min,omitempty"`
 	Max      int32  `json:"max,omitempty"`
+	// Waveform is the shape of the wave. Defaults to sine.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=sine;cosine;square;triangle;sawtooth;pulse;noise
+	Waveform string `json:"waveform,omitempty"`
+	// Phase shifts the wave later through its period, e.g. 75s.
+	// +kubebuilder:validation:Optional
+	Phase string `json:"phase,omitempty"`
+	// Amplitude is the fraction of the min to max range the wave spans around
+	// its middle, as a decimal string, e.g. "0.5". Defaults to the whole range.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^(0(\.[0-9]+)?|1(\.0+)?)$`
+	Amplitude string `json:"amplitude,omitempty"`
+	// DutyCycle is the percentage of the period square and pulse waves spend
+	// high. Defaults to 50 for square and 10 for pulse.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=99
+	DutyCycle int32 `json:"dutyCycle,omitempty"`
+	// Seed picks the noise wave, Ships with the same seed share it.
+	// +kubebuilder:validation:Optional
+	Seed int64 `json:"seed,omitempty"`
+	// Anchor is a timestamp a period starts at, e.g. 2025-01-06T09:00:00 to
+	// start a 168h wave at 9am each Monday. Without an offset it is read in
+	// timezone. Defaults to midnight on 1 January 1970.
+	// +kubebuilder:validation:Optional
+	Anchor string `json:"anchor,omitempty"`
+	// Timezone is the IANA zone periods are counted in, e.g.
+	// Australia/Melbourne. A wave follows its wall clock, so a daily wave
+	// stays aligned to local midnight across daylight saving changes.
+	// Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ScheduleMode targets the replica count of the cron entry which fired most
+// recently, e.g. 10 replicas from "0 9 * * 1-5" and 2 from "0 17 * * 1-5".
+type ScheduleMode struct {
+	// Timezone is the IANA zone used by entries without their own, e.g.
+	// Australia/Melbourne. Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:MinItems=1
+	Entries []ScheduleEntry `json:"entries,omitempty"`
+}
+
+type ScheduleEntry struct {
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// Cron is a standard five field cron expression or a descriptor such as
+	// @daily, at which this entry becomes active.
+	// +kubebuilder:validation:Required
+	Cron string `json:"cron"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+	// Timezone overrides the schedule's timezone for this entry.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// PrometheusMode targets enough conscripts for the result of a PromQL query to
+// stay at or below TargetPerReplica each. ServerURL, Query, TargetPerReplica
+// and MaxReplicas are required in prometheus mode, which the validating
+// webhook checks: the block is always serialized, so the schema can't.
+type PrometheusMode struct {
+	// ServerURL is the Prometheus server, e.g. http://prometheus:9090.
+	// +kubebuilder:validation:Optional
+	ServerURL string `json:"serverURL,omitempty"`
+	// Query is an instant PromQL query which must return a single sample,
+	// e.g. sum(rate(http_requests_total[1m])).
+	// +kubebuilder:validation:Optional
+	Query string `json:"query,omitempty"`
+	// TargetPerReplica is the query value each conscript should handle, as a
+	// decimal string, e.g. "50".
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	TargetPerReplica string `json:"targetPerReplica,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// Interval is how often the query is evaluated, e.g. 1m. Defaults to 30s.
+	// +kubebuilder:validation:Optional
+	Interval string `json:"interval,omitempty"`
+}
+
+// ExternalMode asks an out of process gRPC scaler for the conscript target.
+// The protocol is defined in shared/externalscaler/externalscaler.proto.
+type ExternalMode struct {
+	// Address is the host:port of the scaler, e.g. trig-scaler.freyr:9090.
+	// Required in external mode.
+	// +kubebuilder:validation:Optional
+	Address string `json:"address,omitempty"`
+	// Spec is passed to the scaler untouched.
+	// +kubebuilder:validation:Optional
+	Spec map[string]string `json:"spec,omitempty"`
+	// Timeout bounds each call to the scaler, e.g. 2s. Defaults to 5s. While
+	// the scaler fails spec.scaling.fallbackReplicas applies.
+	// +kubebuilder:validation:Optional
+	Timeout string `json:"timeout,omitempty"`
+	// Interval is how often the scaler is asked for a target, e.g. 1m.
+	// Defaults to 30s.
+	// +kubebuilder:validation:Optional
+	Interval string `json:"interval,omitempty"`
+	// TLS secures the connection to the scaler. It is plaintext when unset.
+	// +kubebuilder:validation:Optional
+	TLS *ExternalTLS `json:"tls,omitempty"`
+}
+
+type ExternalTLS struct {
+	// ServerName overrides the name the scaler's certificate is verified
+	// against.
+	// +kubebuilder:validation:Optional
+	ServerName string `json:"serverName,omitempty"`
+	// +kubebuilder:validation:Optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+	// CASecretRef selects the PEM encoded CA the scaler's certificate is
+	// verified with. The system roots are used when unset.
+	// +kubebuilder:validation:Optional
+	CASecretRef *corev1.SecretKeySelector `json:"caSecretRef,omitempty"`
+	// CertSecretRef names a kubernetes.io/tls Secret whose certificate is
+	// presented to the scaler.
+	// +kubebuilder:validation:Optional
+	CertSecretRef *corev1.LocalObjectReference `json:"certSecretRef,omitempty"`
+}
+
+// CompositeMode combines the targets of several child modes, e.g. the max of a
+// trig wave and a temperature driven count.
+type CompositeMode struct {
+	// Aggregation combines the children's targets. Defaults to max.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=min;max;sum;avg
+	Aggregation string `json:"aggregation,omitempty"`
+	// Children are evaluated independently. A child which fails is left out
+	// of the aggregate. At least one is required in composite mode.
+	// +kubebuilder:validation:Optional
+	Children []CompositeChild `json:"children,omitempty"`
+}
+
+// CompositeChild selects a mode and holds its block of the spec. Composites
+// can't be nested.
+type CompositeChild struct {
+	// Name identifies the child in the Ship status. Defaults to its index.
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:Required
+	Mode string `json:"mode"`
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+	// +kubebuilder:validation:Optional
+	Weather WeatherMode `json:"weather,omitempty"`
+	// +kubebuilder:validation:Optional
+	Trig TrigMode `json:"trig,omitempty"`
+	// +kubebuilder:validation:Optional
+	Schedule ScheduleMode `json:"schedule,omitempty"`
+	// +kubebuilder:validation:Optional
+	Prometheus PrometheusMode `json:"prometheus,omitempty"`
+	// +kubebuilder:validation:Optional
+	External ExternalMode `json:"external,omitempty"`
+	// +kubebuilder:validation:Optional
+	Replay ReplayMode `json:"replay,omitempty"`
+}
+
+// ReplayMode plays back a recorded series of (offset, replicas) points, e.g. a
+// production traffic curve captured as conscript counts.
+type ReplayMode struct {
+	// SeriesConfigMapRef selects the key of a ConfigMap in the Ship's
+	// namespace which holds the series. CSV has an offset and replica count
+	// per row, e.g. "90s,6", JSON is an array of {"offset", "replicas"}.
+	// Offsets are durations or seconds, increasing from 0. Required in replay
+	// mode.
+	// +kubebuilder:validation:Optional
+	SeriesConfigMapRef *corev1.ConfigMapKeySelector `json:"seriesConfigMapRef,omitempty"`
+	// Format of the series. Defaults to json when the key ends in .json and
+	// csv otherwise.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=csv;json
+	Format string `json:"format,omitempty"`
+	// End is what happens after the last point: loop starts the series again,
+	// clamp holds the last point. Defaults to loop.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=loop;clamp
+	End string `json:"end,omitempty"`
+	// Interpolation moves the replica count between points in a straight
+	// line with linear, or holds each point until the next with step.
+	// Defaults to linear.
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum=linear;step
+	Interpolation string `json:"interpolation,omitempty"`
+	// Anchor is the timestamp of offset 0, e.g. 2025-01-06T00:00:00 to start
+	// a week long series each Monday. Without an offset it is read in
+	// timezone. Defaults to midnight on 1 January 1970.
+	// +kubebuilder:validation:Optional
+	Anchor string `json:"anchor,omitempty"`
+	// Timezone is the IANA zone offsets are counted in. Defaults to UTC.
+	// +kubebuilder:validation:Optional
+	Timezone string `json:"timezone,omitempty"`
 }
 
+// ModeContribution is the target one child of a composite mode came up with.
+type ModeContribution struct {
+	Name   string `json:"name"`
+	Mode   string `json:"mode"`
+	Target int32  `json:"target"`
+	// Error is why the child failed to evaluate, in which case it did not
+	// contribute.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// ForecastChange is a target a mode predicts it will change to.
+type ForecastChange struct {
+	At       metav1.Time `json:"at"`
+	Replicas int32       `json:"replicas"`
+}
+
+// Condition types reported on ShipStatus.Conditions.
+const (
+	// ConditionReady is True when the captain is available, the mode evaluated
+	// successfully and the conscripts have reached their target.
+	ConditionReady = "Ready"
+	// ConditionCaptainAvailable is True when the captain Deployment has at least
+	// one available replica.
+	ConditionCaptainAvailable = "CaptainAvailable"
+	// ConditionConscriptsScaled is True when the ready conscript replicas match
+	// the target computed by the active mode.
+	ConditionConscriptsScaled = "ConscriptsScaled"
+	// ConditionModeHealthy is True when the active mode produced a target
+	// without error.
+	ConditionModeHealthy = "ModeHealthy"
+)
+
 // ShipStatus defines the observed state of Ship
 type ShipStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// ObservedGeneration is the most recent Ship generation reconciled by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions describe the current state of the Ship.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Mode is the scaling mode which produced TargetConscripts.
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// TargetConscripts is the conscript replica count computed on the last reconcile.
+	// +optional
+	TargetConscripts int32 `json:"targetConscripts,omitempty"`
+
+	// ObservedConscripts is the number of ready conscript replicas.
+	// +optional
+	ObservedConscripts int32 `json:"observedConscripts,omitempty"`
+
+	// LastScaleTime is the last time the conscript Deployment was rescaled.
+	// +optional
+	LastScaleTime *metav1.Time `json:"lastScaleTime,omitempty"`
+	// Contributions are the targets of each child of a composite mode.
+	// +optional
+	Contributions []ModeContribution `json:"contributions,omitempty"`
+
+	// Forecast previews the scale changes the mode predicts over the next
+	// day, before the rate limits of the scaling policy. It is empty for modes
+	// which can't forecast, such as prometheus.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	Forecast []ForecastChange `json:"forecast,omitempty"`
+
+	// Replicas is the current number of conscript replicas, reported through
+	// the scale subresource.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector is the label selector of the conscript pods, reported through
+	// the scale subresource.
+	// +optional
+	Selector string `json:"selector,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Mode",type=string,JSONPath=`.status.mode`
+// +kubebuilder:printcolumn:name="Target",type=integer,JSONPath=`.status.targetConscripts`
+// +kubebuilder:printcolumn:name="Observed",type=integer,JSONPath=`.status.observedConscripts`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Last Scale",type=date,JSONPath=`.status.lastScaleTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Ship is the Schema for the ships API
 type Ship struct {
@@ -21,9 +21,142 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeChild) DeepCopyInto(out *CompositeChild) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Weather.DeepCopyInto(&out.Weather)
+	out.Trig = in.Trig
+	in.Schedule.DeepCopyInto(&out.Schedule)
+	out.Prometheus = in.Prometheus
+	in.External.DeepCopyInto(&out.External)
+	in.Replay.DeepCopyInto(&out.Replay)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeChild.
+func (in *CompositeChild) DeepCopy() *CompositeChild {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeChild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeMode) DeepCopyInto(out *CompositeMode) {
+	*out = *in
+	if in.Children != nil {
+		in, out := &in.Children, &out.Children
+		*out = make([]CompositeChild, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeMode.
+func (in *CompositeMode) DeepCopy() *CompositeMode {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalMode) DeepCopyInto(out *ExternalMode) {
+	*out = *in
+	if in.Spec != nil {
+		in, out := &in.Spec, &out.Spec
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ExternalTLS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalMode.
+func (in *ExternalMode) DeepCopy() *ExternalMode {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalTLS) DeepCopyInto(out *ExternalTLS) {
+	*out = *in
+	if in.CASecretRef != nil {
+		in, out := &in.CASecretRef, &out.CASecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CertSecretRef != nil {
+		in, out := &in.CertSecretRef, &out.CertSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalTLS.
+func (in *ExternalTLS) DeepCopy() *ExternalTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ForecastChange) DeepCopyInto(out *ForecastChange) {
+	*out = *in
+	in.At.DeepCopyInto(&out.At)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ForecastChange.
+func (in *ForecastChange) DeepCopy() *ForecastChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ForecastChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModeContribution) DeepCopyInto(out *ModeContribution) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModeContribution.
+func (in *ModeContribution) DeepCopy() *ModeContribution {
+	if in == nil {
+		return nil
+	}
+	out := new(ModeContribution)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSpec) DeepCopyInto(out *PodSpec) {
 	*out = *in
@@ -46,13 +179,135 @@ func (in *PodSpec) DeepCopy() *PodSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PrometheusMode) DeepCopyInto(out *PrometheusMode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrometheusMode.
+func (in *PrometheusMode) DeepCopy() *PrometheusMode {
+	if in == nil {
+		return nil
+	}
+	out := new(PrometheusMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplayMode) DeepCopyInto(out *ReplayMode) {
+	*out = *in
+	if in.SeriesConfigMapRef != nil {
+		in, out := &in.SeriesConfigMapRef, &out.SeriesConfigMapRef
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplayMode.
+func (in *ReplayMode) DeepCopy() *ReplayMode {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplayMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingPolicy) DeepCopyInto(out *ScalingPolicy) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FallbackReplicas != nil {
+		in, out := &in.FallbackReplicas, &out.FallbackReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.ScaleUp.DeepCopyInto(&out.ScaleUp)
+	in.ScaleDown.DeepCopyInto(&out.ScaleDown)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingPolicy.
+func (in *ScalingPolicy) DeepCopy() *ScalingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScalingRules) DeepCopyInto(out *ScalingRules) {
+	*out = *in
+	if in.MaxStep != nil {
+		in, out := &in.MaxStep, &out.MaxStep
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScalingRules.
+func (in *ScalingRules) DeepCopy() *ScalingRules {
+	if in == nil {
+		return nil
+	}
+	out := new(ScalingRules)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleEntry) DeepCopyInto(out *ScheduleEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleEntry.
+func (in *ScheduleEntry) DeepCopy() *ScheduleEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduleMode) DeepCopyInto(out *ScheduleMode) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]ScheduleEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduleMode.
+func (in *ScheduleMode) DeepCopy() *ScheduleMode {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduleMode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ship) DeepCopyInto(out *Ship) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ship.
@@ -108,8 +363,19 @@ func (in *ShipList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShipSpec) DeepCopyInto(out *ShipSpec) {
 	*out = *in
-	out.Weather = in.Weather
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Scaling.DeepCopyInto(&out.Scaling)
+	in.Weather.DeepCopyInto(&out.Weather)
 	out.Trig = in.Trig
+	in.Schedule.DeepCopyInto(&out.Schedule)
+	out.Prometheus = in.Prometheus
+	in.External.DeepCopyInto(&out.External)
+	in.Composite.DeepCopyInto(&out.Composite)
+	in.Replay.DeepCopyInto(&out.Replay)
 	in.Captain.DeepCopyInto(&out.Captain)
 	in.Conscript.DeepCopyInto(&out.Conscript)
 	if in.EnvVars != nil {
@@ -134,6 +400,29 @@ func (in *ShipSpec) DeepCopy() *ShipSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ShipStatus) DeepCopyInto(out *ShipStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastScaleTime != nil {
+		in, out := &in.LastScaleTime, &out.LastScaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Contributions != nil {
+		in, out := &in.Contributions, &out.Contributions
+		*out = make([]ModeContribution, len(*in))
+		copy(*out, *in)
+	}
+	if in.Forecast != nil {
+		in, out := &in.Forecast, &out.Forecast
+		*out = make([]ForecastChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ShipStatus.
@@ -161,9 +450,35 @@ func (in *TrigMode) DeepCopy() *TrigMode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeatherMapping) DeepCopyInto(out *WeatherMapping) {
+	*out = *in
+	if in.Thresholds != nil {
+		in, out := &in.Thresholds, &out.Thresholds
+		*out = make([]WeatherThreshold, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeatherMapping.
+func (in *WeatherMapping) DeepCopy() *WeatherMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(WeatherMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WeatherMode) DeepCopyInto(out *WeatherMode) {
 	*out = *in
+	if in.APIKeySecretRef != nil {
+		in, out := &in.APIKeySecretRef, &out.APIKeySecretRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Mapping.DeepCopyInto(&out.Mapping)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeatherMode.
@@ -175,3 +490,18 @@ func (in *WeatherMode) DeepCopy() *WeatherMode {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WeatherThreshold) DeepCopyInto(out *WeatherThreshold) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WeatherThreshold.
+func (in *WeatherThreshold) DeepCopy() *WeatherThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(WeatherThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -20,12 +20,10 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
-	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -37,8 +35,11 @@ import (
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/socialviolation/freyr/shared/openweather"
+	"github.com/socialviolation/freyr/shared/scaling"
 	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
 	"github.com/socialviolation/freyr/ship-operator/internal/controller"
+	webhookfreyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -71,6 +72,14 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.StringVar(&openweather.DefaultClient.BaseURL, "openweather-url", openweather.DefaultBaseURL,
+		"The OpenWeather API used by weather mode, e.g. a local fake for tests and demos.")
+	flag.DurationVar(&openweather.DefaultClient.WeatherTTL, "openweather-cache-ttl", openweather.DefaultWeatherTTL,
+		"How long the current weather of a location is reused across Ships.")
+	flag.DurationVar(&openweather.DefaultClient.ForecastTTL, "openweather-forecast-cache-ttl", openweather.DefaultForecastTTL,
+		"How long the weather forecast of a location is reused across Ships.")
+	flag.StringVar(&scaling.FakeWeatherDir, "fake-weather-dir", "",
+		"The directory fake weather providers may read file scripts from. Left empty, they only read http(s) URLs.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -122,7 +131,6 @@ func main() {
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
 
-	sp := time.Second * 10
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
@@ -141,9 +149,6 @@ func main() {
 		// if you are doing or is intended to do any operation such as perform cleanups
 		// after the manager stops then its usage might be unsafe.
 		// LeaderElectionReleaseOnCancel: true,
-		Cache: cache.Options{
-			SyncPeriod: &sp,
-		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -151,12 +156,20 @@ func main() {
 	}
 
 	if err = (&controller.ShipReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("ship-operator"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Ship")
 		os.Exit(1)
 	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = webhookfreyrv1alpha1.SetupShipWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Ship")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,159 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/socialviolation/freyr/shared"
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+// configMapRefIndex indexes Ships by the names of the ConfigMaps they
+// reference, such as a replay series, so an edited series can be mapped back
+// to its Ships.
+const configMapRefIndex = ".spec.configMapRefs"
+
+func indexConfigMapRefs(obj client.Object) []string {
+	ship, ok := obj.(*freyrv1alpha1.Ship)
+	if !ok {
+		return nil
+	}
+	var names []string
+	if ref := ship.Spec.Replay.SeriesConfigMapRef; ref != nil {
+		names = append(names, ref.Name)
+	}
+	for _, child := range ship.Spec.Composite.Children {
+		if ref := child.Replay.SeriesConfigMapRef; ref != nil {
+			names = append(names, ref.Name)
+		}
+	}
+	return names
+}
+
+// shipsForConfigMap enqueues every Ship in the ConfigMap's namespace which
+// references that ConfigMap.
+func (r *ShipReconciler) shipsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	ships := &freyrv1alpha1.ShipList{}
+	err := r.List(ctx, ships, client.InNamespace(obj.GetNamespace()), client.MatchingFields{configMapRefIndex: obj.GetName()})
+	if err != nil {
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(ships.Items))
+	for _, ship := range ships.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: ship.GetName(), Namespace: ship.GetNamespace()}})
+	}
+	return reqs
+}
+
+// resolveConfigMapRefs copies the values of any ConfigMap references in the
+// Ship into the inline fields of the operator spec handed to the scaling mode.
+func (r *ShipReconciler) resolveConfigMapRefs(ctx context.Context, ship *freyrv1alpha1.Ship, spec *shared.OperatorSpec) error {
+	err := r.resolveReplaySeries(ctx, ship, "", ship.Spec.Replay, &spec.Replay)
+	if err != nil {
+		return err
+	}
+	for i, child := range ship.Spec.Composite.Children {
+		if i >= len(spec.Composite.Children) {
+			break
+		}
+		prefix := fmt.Sprintf("composite.children[%d].", i)
+		err = r.resolveReplaySeries(ctx, ship, prefix, child.Replay, &spec.Composite.Children[i].Replay)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ShipReconciler) resolveReplaySeries(ctx context.Context, ship *freyrv1alpha1.Ship, prefix string,
+	replay freyrv1alpha1.ReplayMode, replaySpec *shared.ReplayMode) error {
+	ref := replay.SeriesConfigMapRef
+	if ref == nil {
+		return nil
+	}
+	series, err := r.configMapKey(ctx, ship, ref, prefix+"replay.seriesConfigMapRef")
+	if err != nil {
+		return err
+	}
+	replaySpec.Series = series
+	return nil
+}
+
+// configMapKey reads the key selected by ref from a ConfigMap in the Ship's
+// namespace, from its data or binary data. A missing optional ConfigMap or key
+// returns "".
+func (r *ShipReconciler) configMapKey(ctx context.Context, ship *freyrv1alpha1.Ship, ref *corev1.ConfigMapKeySelector, field string) (string, error) {
+	optional := ref.Optional != nil && *ref.Optional
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ship.GetNamespace()}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) && optional {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", field, err)
+	}
+
+	if value, ok := cm.Data[ref.Key]; ok {
+		return value, nil
+	}
+	if value, ok := cm.BinaryData[ref.Key]; ok {
+		return string(value), nil
+	}
+	if !optional {
+		return "", fmt.Errorf("configmap %s has no key %q for %s", ref.Name, ref.Key, field)
+	}
+	return "", nil
+}
+
+// seriesVolume projects the replay series the Ship references into the
+// captain, which has no access to ConfigMaps, at shared.SeriesDir. Every
+// source is optional, so a missing series fails the mode on the Ship rather
+// than stopping the captain from starting.
+func seriesVolume(ship *freyrv1alpha1.Ship) (corev1.Volume, bool) {
+	var sources []corev1.VolumeProjection
+	project := func(ref *corev1.ConfigMapKeySelector, path string) {
+		if ref == nil {
+			return
+		}
+		sources = append(sources, corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{
+			LocalObjectReference: ref.LocalObjectReference,
+			Items:                []corev1.KeyToPath{{Key: ref.Key, Path: path}},
+			Optional:             ptr.To(true),
+		}})
+	}
+	project(ship.Spec.Replay.SeriesConfigMapRef, shared.ReplaySeriesFile)
+	for i, child := range ship.Spec.Composite.Children {
+		project(child.Replay.SeriesConfigMapRef, shared.ChildReplaySeriesFile(i))
+	}
+	if len(sources) == 0 {
+		return corev1.Volume{}, false
+	}
+	return corev1.Volume{
+		Name:         "series",
+		VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: sources}},
+	}, true
+}
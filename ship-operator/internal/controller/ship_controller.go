@@ -18,9 +18,13 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -31,24 +35,44 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	"github.com/socialviolation/freyr/shared/openweather"
-	"github.com/socialviolation/freyr/shared/trig"
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/scaling"
 	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
 )
 
+const (
+	// fieldManager owns every field the operator server-side applies.
+	fieldManager = "ship-operator"
+	// configHashAnnotation on the captain pod template rolls the captain when
+	// the Ship ConfigMap changes.
+	configHashAnnotation = "freyr.fmtl.au/config-hash"
+	captainPort          = int32(5001)
+)
+
 // ShipReconciler reconciles a Ship object
 type ShipReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Clock is what the reconciler takes to be now, defaulting to the system
+	// time. Tests and simulations set a fake one to step through time.
+	Clock clock.Clock
+
+	historyMu sync.Mutex
+	histories map[types.NamespacedName]*scaling.History
 }
 
 // +kubebuilder:rbac:groups=freyr.fmtl.au,resources=ships,verbs=get;list;watch;create;update;patch;delete
@@ -57,6 +81,8 @@ type ShipReconciler struct {
 // +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -80,6 +106,7 @@ func (r *ShipReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	if err != nil {
 		if errors.IsNotFound(err) {
 			log.Info("Ship resource not deployed. Ignoring since object must be deleted")
+			r.forgetHistory(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -87,177 +114,206 @@ func (r *ShipReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 		return ctrl.Result{}, err
 	}
 
+	if !ship.GetDeletionTimestamp().IsZero() {
+		return r.finalize(ctx, ship)
+	}
+	err = r.ensureFinalizer(ctx, ship)
+	if err != nil {
+		log.Error(err, "Failed to add Ship finalizer")
+		return ctrl.Result{}, err
+	}
+
 	log.Info("Reconciling Ship")
+	orig := ship.DeepCopy()
+	ship.Status.ObservedGeneration = ship.GetGeneration()
+	ship.Status.Mode = ship.Spec.Mode
 
 	captainUrl := fmt.Sprintf("http://%s.%s.svc.cluster.local:80", ship.GetName(), ns)
-	opJson, err := json.Marshal(ship.Spec)
+	opJson, err := json.Marshal(redactedSpec(ship))
 	if err != nil {
 		log.Error(err, "Failed to marshal Ship spec")
 		return ctrl.Result{}, err
 	}
 
-	confName := fmt.Sprintf("%s-config", ship.GetName())
-	configMap := &corev1.ConfigMap{}
-	err = r.Get(ctx, types.NamespacedName{Name: confName, Namespace: ns}, configMap)
-	if err != nil && errors.IsNotFound(err) {
-		cm := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      confName,
-				Namespace: req.Namespace,
-				Labels: map[string]string{
-					"app.kubernetes.io/managed-by": "ship-operator",
-					"app.kubernetes.io/owner":      ship.GetName(),
-					"app.kubernetes.io/owner-ns":   ship.GetNamespace(),
-				},
-			},
-			Data: map[string]string{
-				"CAPTAIN_URL":     captainUrl,
-				"OPERATOR_CONFIG": string(opJson),
-				"NAME":            ship.GetName(),
-				"NAMESPACE":       ship.GetNamespace(),
-			},
-		}
-
-		err = r.Create(ctx, cm)
-		if err != nil {
-			log.Error(err, "Failed to create new ConfigMap")
-			return ctrl.Result{}, err
-		}
-		log.Info("Created a new ConfigMap")
-		return ctrl.Result{}, nil
+	// Every owned resource is rendered from the Ship and server-side applied
+	// on each reconcile, so spec changes roll out and manual edits to fields
+	// owned by the operator are reverted.
+	configMap, err := r.configMapForShip(ship, captainUrl, string(opJson))
+	if err != nil {
+		log.Error(err, "Failed to define ConfigMap")
+		return ctrl.Result{}, err
 	}
+	prev, err := r.apply(ctx, configMap)
+	if err != nil {
+		log.Error(err, "Failed to apply ConfigMap")
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonApplyFailed, "Failed to apply ConfigMap: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.recordApplied(ship, "ConfigMap", configMap, prev)
+	r.recordConfigRollout(ship, configMap, prev)
 
-	captainDep := &appsv1.Deployment{}
-	err = r.Get(ctx, types.NamespacedName{Name: ship.GetName() + "-captain", Namespace: ns}, captainDep)
-	if err != nil && errors.IsNotFound(err) {
-		dep := r.deploymentForCaptain(ship, configMap)
-		if dep == nil {
-			log.Error(err, "Failed to create new Captain Deployment")
-			return ctrl.Result{}, err
-		}
+	captainDep, err := r.deploymentForCaptain(ship, configMap)
+	if err != nil {
+		log.Error(err, "Failed to define Captain Deployment")
+		return ctrl.Result{}, err
+	}
+	prev, err = r.apply(ctx, captainDep)
+	if err != nil {
+		log.Error(err, "Failed to apply Captain Deployment")
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonApplyFailed, "Failed to apply Captain Deployment: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.recordApplied(ship, "Deployment", captainDep, prev)
 
-		log.Info("Creating a new Captain Deployment")
-		err = r.Create(ctx, dep)
-		if err != nil {
-			log.Error(err, "Failed to create new Captain Deployment")
-			return ctrl.Result{}, err
-		}
+	captainSvc, err := r.serviceForCaptain(ship, captainPort)
+	if err != nil {
+		log.Error(err, "Failed to define Captain Service")
+		return ctrl.Result{}, err
+	}
+	prev, err = r.apply(ctx, captainSvc)
+	if err != nil {
+		log.Error(err, "Failed to apply Captain Service")
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonApplyFailed, "Failed to apply Captain Service: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.recordApplied(ship, "Service", captainSvc, prev)
+
+	// The current replica count is only needed to tell whether this reconcile
+	// rescales the conscripts.
+	currentConscripts := int32(-1)
+	existing := &appsv1.Deployment{}
+	err = r.Get(ctx, types.NamespacedName{Name: ship.GetName() + "-conscript", Namespace: ns}, existing)
+	if err == nil && existing.Spec.Replicas != nil {
+		currentConscripts = *existing.Spec.Replicas
+	} else if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get Conscript Deployment")
+		return ctrl.Result{}, err
+	}
 
-		return ctrl.Result{}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Captain Deployment")
+	spec, err := operatorSpec(ship)
+	if err != nil {
+		log.Error(err, "Failed to convert Ship spec")
 		return ctrl.Result{}, err
 	}
+	eval, modeErr := r.evaluateMode(ctx, ship, &spec)
+	recommended := eval.target
+	ship.Status.Contributions = contributionStatus(eval.contributions)
+	ship.Status.Forecast = forecastStatus(eval.forecast)
+	if modeErr != nil {
+		log.Error(modeErr, "Failed to evaluate scaling mode", "mode", ship.Spec.Mode)
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonModeFailed, "Mode %q failed: %v", ship.Spec.Mode, modeErr)
+		recommended = scaling.FallbackTarget(spec.Scaling, currentConscripts)
+	}
+	targetConscripts := r.applyPolicy(req.NamespacedName, spec.Scaling, currentConscripts, recommended)
+	log.Info("Reconciling scaling mode", "mode", ship.Spec.Mode, "recommended", recommended, "target", targetConscripts, "actual", currentConscripts)
 
-	// Captain Service
-	captainSvc := &corev1.Service{}
-	err = r.Get(ctx, types.NamespacedName{Name: ship.GetName(), Namespace: ship.GetNamespace()}, captainSvc)
-	if err != nil && errors.IsNotFound(err) {
-		svc := r.serviceForCaptain(ship, captainDep.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort)
-		log.Info("Creating a new Captain Service")
-		err = r.Create(ctx, svc)
-		if err != nil {
-			log.Error(err, "Failed to create new Captain Service")
-			return ctrl.Result{}, err
-		}
-		return ctrl.Result{}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Captain Service")
+	conscriptDep, err := r.deploymentForConscript(ship, targetConscripts)
+	if err != nil {
+		log.Error(err, "Failed to define Conscript Deployment")
+		return ctrl.Result{}, err
+	}
+	prev, err = r.apply(ctx, conscriptDep)
+	if err != nil {
+		log.Error(err, "Failed to apply Conscript Deployment")
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonApplyFailed, "Failed to apply Conscript Deployment: %v", err)
 		return ctrl.Result{}, err
 	}
+	r.recordApplied(ship, "Deployment", conscriptDep, prev)
+	r.recordScale(ship, currentConscripts, targetConscripts, modeErr)
 
-	// Conscript
-	conscriptDep := &appsv1.Deployment{}
-	err = r.Get(ctx, types.NamespacedName{Name: ship.GetName() + "-conscript", Namespace: ns}, conscriptDep)
-	if err != nil && errors.IsNotFound(err) {
-		// Define a new deployment
-		dep := r.deploymentForConscript(ship)
-		if dep == nil {
-			log.Error(err, "Failed to create new Conscript Deployment")
-			return ctrl.Result{}, err
-		}
-		log.Info("Creating a new Conscript Deployment")
-		err = r.Create(ctx, dep)
-		if err != nil {
-			log.Error(err, "Failed to create new Conscript Deployment")
-			return ctrl.Result{}, err
-		}
-		// Deployment created successfully - return and requeue
-		return ctrl.Result{}, nil
-	} else if err != nil {
-		log.Error(err, "Failed to get Conscript Deployment")
+	evaluationMap, err := r.configMapForEvaluation(ship, targetConscripts, eval.contributions)
+	if err != nil {
+		log.Error(err, "Failed to define evaluation ConfigMap")
 		return ctrl.Result{}, err
 	}
+	prev, err = r.apply(ctx, evaluationMap)
+	if err != nil {
+		log.Error(err, "Failed to apply evaluation ConfigMap")
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonApplyFailed, "Failed to apply evaluation ConfigMap: %v", err)
+		return ctrl.Result{}, err
+	}
+	r.recordApplied(ship, "ConfigMap", evaluationMap, prev)
+	if currentConscripts != targetConscripts {
+		now := metav1.NewTime(r.clk().Now())
+		ship.Status.LastScaleTime = &now
+	}
 
-	if configMap.Data["CAPTAIN_URL"] != captainUrl || configMap.Data["OPERATOR_CONFIG"] != string(opJson) {
-		log.Info("Updating ConfigMap")
-		configMap.Data["CAPTAIN_URL"] = captainUrl
-		configMap.Data["OPERATOR_CONFIG"] = string(opJson)
-		err = r.Update(ctx, configMap)
-		if err != nil {
-			log.Error(err, "Failed to update ConfigMap")
-			return ctrl.Result{Requeue: false}, err
-		}
+	setShipConditions(ship, captainDep, conscriptDep, targetConscripts, modeErr)
+	err = r.patchStatus(ctx, ship, orig)
+	if err != nil {
+		log.Error(err, "Failed to update Ship status")
+		return ctrl.Result{}, err
+	}
 
-		if captainDep.Spec.Template.ObjectMeta.Annotations == nil {
-			captainDep.Spec.Template.ObjectMeta.Annotations = map[string]string{}
-		}
+	return requeueResult(r.clk().Now(), eval.next, modeErr != nil, targetConscripts != recommended), nil
+}
 
-		captainDep.Spec.Template.ObjectMeta.Annotations["kubectl.kubernetes.io/restartedAt"] = time.Now().Format(time.RFC3339)
-		err = r.Update(ctx, captainDep)
-		if err != nil {
-			log.Error(err, "Failed to update Captain Deployment")
-			return ctrl.Result{}, err
-		}
+func (r *ShipReconciler) clk() clock.Clock {
+	if r.Clock == nil {
+		return clock.Real{}
 	}
+	return r.Clock
+}
 
-	if *captainDep.Spec.Replicas != 1 {
-		rep := int32(1)
-		captainDep.Spec.Replicas = &rep
-		err = r.Update(ctx, captainDep)
-		if err != nil {
-			log.Error(err, "Failed to update Captain Deployment")
-			return ctrl.Result{}, err
-		}
+// operatorSpec converts the Ship spec into the shared.OperatorSpec understood
+// by the scaling modes. The two share a JSON shape, which is also what the
+// captain decodes from the ConfigMap.
+func operatorSpec(ship *freyrv1alpha1.Ship) (shared.OperatorSpec, error) {
+	spec := shared.OperatorSpec{}
+	opJson, err := json.Marshal(ship.Spec)
+	if err != nil {
+		return spec, err
 	}
+	err = json.Unmarshal(opJson, &spec)
+	return spec, err
+}
 
-	targetConscripts := int32(1)
-	if ship.Spec.Mode == "weather" {
-		l := openweather.Location{
-			Country: ship.Spec.Weather.Country,
-			City:    ship.Spec.Weather.City,
-		}
-		llt, err := openweather.GetTempByCountry(ship.Spec.Weather.APIKey, l)
-		if err != nil {
-			log.Error(err, "Failed to retrieve weather")
-		}
-		targetConscripts = llt.Temp
-		log.Info("Reconciling Weather mode", "conscripts", targetConscripts)
-	} else if ship.Spec.Mode == "trig" {
-		args := trig.Args{
-			Duration: ship.Spec.Trig.Duration,
-			Min:      ship.Spec.Trig.Min,
-			Max:      ship.Spec.Trig.Max,
-		}
-		fv, err := trig.GetValue(args)
-		if err != nil {
-			log.Error(err, "Failed to retrieve trig value")
-		} else {
-			targetConscripts = int32(fv)
-		}
-		log.Info("Reconciling Trig mode", "target", targetConscripts, "actual", conscriptDep.Spec.Replicas, "duration", ship.Spec.Trig.Duration, "min", ship.Spec.Trig.Min, "max", ship.Spec.Trig.Max)
-	}
+// evaluation is the outcome of evaluating a Ship's scaling mode.
+type evaluation struct {
+	target int32
+	// next is when the mode expects the target to next change.
+	next time.Time
+	// contributions break the target down for modes combining other modes.
+	contributions []scaling.Contribution
+	// forecast is the changes the mode predicts over the next day.
+	forecast []scaling.ForecastPoint
+}
 
-	if *conscriptDep.Spec.Replicas != targetConscripts {
-		conscriptDep.Spec.Replicas = &targetConscripts
-		err = r.Update(ctx, conscriptDep)
-		if err != nil {
-			log.Error(err, "Failed to update Conscript Deployment")
-			return ctrl.Result{}, err
-		}
+// evaluateMode computes the conscript target using the ScalingMode registered
+// for the Ship's spec.mode.
+func (r *ShipReconciler) evaluateMode(ctx context.Context, ship *freyrv1alpha1.Ship, spec *shared.OperatorSpec) (evaluation, error) {
+	eval := evaluation{}
+	err := r.resolveSecretRefs(ctx, ship, spec)
+	if err != nil {
+		return eval, err
+	}
+	err = r.resolveConfigMapRefs(ctx, ship, spec)
+	if err != nil {
+		return eval, err
 	}
 
-	return ctrl.Result{}, nil
+	mode, err := scaling.Lookup(spec.Mode)
+	if err != nil {
+		return eval, err
+	}
+	err = mode.Validate(*spec)
+	if err != nil {
+		return eval, err
+	}
+	clk := r.clk()
+	ctx = scaling.WithShip(ctx, scaling.Ship{Name: ship.GetName(), Namespace: ship.GetNamespace()})
+	if explainer, ok := mode.(scaling.Explainer); ok {
+		eval.target, eval.contributions, err = explainer.Explain(ctx, *spec, clk)
+	} else {
+		eval.target, err = mode.Target(ctx, *spec, clk)
+	}
+	if err != nil {
+		return eval, err
+	}
+	eval.target = lead(ctx, *spec, eval.target, clk)
+	eval.next = nextEvaluation(mode, *spec, clk)
+	eval.forecast = forecast(ctx, *spec, clk)
+	return eval, nil
 }
 
 func safeSetControllerReference(owner, object client.Object, scheme *runtime.Scheme) error {
@@ -269,12 +325,23 @@ func safeSetControllerReference(owner, object client.Object, scheme *runtime.Sch
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *ShipReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &freyrv1alpha1.Ship{}, secretRefIndex, indexSecretRefs)
+	if err != nil {
+		return err
+	}
+	err = mgr.GetFieldIndexer().IndexField(context.Background(), &freyrv1alpha1.Ship{}, configMapRefIndex, indexConfigMapRefs)
+	if err != nil {
+		return err
+	}
+
 	b := false
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&freyrv1alpha1.Ship{}).
 		Owns(&appsv1.Deployment{}, builder.WithPredicates(IgnoreReplicasOnlyUpdate)).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.ConfigMap{}).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.shipsForSecret)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.shipsForConfigMap)).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 1,
 			NeedLeaderElection:      &b,
@@ -282,20 +349,151 @@ func (r *ShipReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
-func (r *ShipReconciler) deploymentForCaptain(ship *freyrv1alpha1.Ship, config *corev1.ConfigMap) *appsv1.Deployment {
-	replicas := int32(1)
-	ls := map[string]string{
-		"app":                          "captain",
+// apply server-side applies obj as the operator's field manager. obj must be
+// fully populated, including its TypeMeta, and is updated with the result.
+// The object as it was before the apply is returned, or nil if the apply
+// created it.
+func (r *ShipReconciler) apply(ctx context.Context, obj client.Object) (client.Object, error) {
+	prev, ok := obj.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", obj)
+	}
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), prev)
+	if errors.IsNotFound(err) {
+		prev = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	err = r.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+	if err != nil {
+		return nil, err
+	}
+	return prev, nil
+}
+
+func shipLabels(ship *freyrv1alpha1.Ship) map[string]string {
+	return map[string]string{
 		"app.kubernetes.io/managed-by": "ship-operator",
 		"app.kubernetes.io/owner":      ship.GetName(),
 		"app.kubernetes.io/owner-ns":   ship.GetNamespace(),
 	}
+}
+
+func podLabels(ship *freyrv1alpha1.Ship, app string) map[string]string {
+	ls := shipLabels(ship)
+	ls["app"] = app
+	return ls
+}
+
+// envVars appends the entries of each map to env in sorted order. Later maps
+// override earlier ones, so role specific envs win over Ship wide envs. The
+// order has to be stable or every apply would roll the pods.
+func envVars(env []corev1.EnvVar, maps ...map[string]string) []corev1.EnvVar {
+	merged := map[string]string{}
+	for _, m := range maps {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		env = append(env, corev1.EnvVar{Name: k, Value: merged[k]})
+	}
+	return env
+}
+
+// configHash fingerprints the ConfigMap data so the captain is rolled when
+// its configuration changes.
+func configHash(cm *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(cm.Data))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, cm.Data[k])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (r *ShipReconciler) configMapForShip(ship *freyrv1alpha1.Ship, captainUrl, opJson string) (*corev1.ConfigMap, error) {
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ship.GetName() + "-config",
+			Namespace: ship.GetNamespace(),
+			Labels:    shipLabels(ship),
+		},
+		Data: map[string]string{
+			"CAPTAIN_URL":     captainUrl,
+			"OPERATOR_CONFIG": opJson,
+			"NAME":            ship.GetName(),
+			"NAMESPACE":       ship.GetNamespace(),
+		},
+	}
+
+	err := safeSetControllerReference(ship, cm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
+
+// configMapForEvaluation publishes the target and composite contributions to
+// the captain, which mounts it rather than evaluating modes whose Secrets it
+// can't read. It holds no timestamp, so applying an unchanged evaluation is a
+// no-op and doesn't trigger another reconcile.
+func (r *ShipReconciler) configMapForEvaluation(ship *freyrv1alpha1.Ship, target int32, contributions []scaling.Contribution) (*corev1.ConfigMap, error) {
+	evaluation := shared.Evaluation{Target: target}
+	for _, c := range contributionStatus(contributions) {
+		evaluation.Contributions = append(evaluation.Contributions, shared.Contribution{
+			Name: c.Name, Mode: c.Mode, Target: c.Target, Error: c.Error,
+		})
+	}
+	data, err := json.Marshal(evaluation)
+	if err != nil {
+		return nil, err
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ship.GetName() + "-evaluation",
+			Namespace: ship.GetNamespace(),
+			Labels:    shipLabels(ship),
+		},
+		Data: map[string]string{
+			shared.EvaluationFile: string(data),
+		},
+	}
+
+	err = safeSetControllerReference(ship, cm, r.Scheme)
+	if err != nil {
+		return nil, err
+	}
+
+	return cm, nil
+}
+
+func (r *ShipReconciler) deploymentForCaptain(ship *freyrv1alpha1.Ship, config *corev1.ConfigMap) (*appsv1.Deployment, error) {
+	replicas := int32(1)
+	ls := podLabels(ship, "captain")
 
 	if ship.Spec.Captain.Image == "" {
-		ship.Spec.Captain.Image = "australia-southeast2-docker.pkg.dev/freyr-operator/imgs/captain:latest"
+		ship.Spec.Captain.Image = freyrv1alpha1.DefaultCaptainImage
 	}
 
 	dep := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ship.GetName() + "-captain",
 			Namespace: ship.GetNamespace(),
@@ -308,13 +506,16 @@ func (r *ShipReconciler) deploymentForCaptain(ship *freyrv1alpha1.Ship, config *
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: ls,
+					Annotations: map[string]string{
+						configHashAnnotation: configHash(config),
+					},
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{{
 						Image: ship.Spec.Captain.Image,
 						Name:  ship.GetName() + "-captain",
 						Ports: []corev1.ContainerPort{{
-							ContainerPort: 5001,
+							ContainerPort: captainPort,
 						}},
 						ImagePullPolicy: corev1.PullIfNotPresent,
 						Resources: corev1.ResourceRequirements{
@@ -323,52 +524,66 @@ func (r *ShipReconciler) deploymentForCaptain(ship *freyrv1alpha1.Ship, config *
 								corev1.ResourceMemory: resource.MustParse("256Mi"),
 							},
 						},
-						Env: []corev1.EnvVar{
+						Env: envVars([]corev1.EnvVar{
 							{Name: "NAME", Value: ship.GetName()},
 							{Name: "NAMESPACE", Value: ship.GetNamespace()},
-						},
+						}, ship.Spec.EnvVars, ship.Spec.Captain.EnvVars),
 						EnvFrom: []corev1.EnvFromSource{{
 							ConfigMapRef: &corev1.ConfigMapEnvSource{
 								LocalObjectReference: corev1.LocalObjectReference{
-									Name: ship.GetName() + "-config",
+									Name: config.GetName(),
 								},
 							},
 						}},
+						VolumeMounts: []corev1.VolumeMount{{
+							Name:      "evaluation",
+							MountPath: shared.EvaluationDir,
+							ReadOnly:  true,
+						}},
+					}},
+					// The evaluation ConfigMap is only applied once the mode
+					// has been evaluated, after the captain.
+					Volumes: []corev1.Volume{{
+						Name: "evaluation",
+						VolumeSource: corev1.VolumeSource{
+							ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: ship.GetName() + "-evaluation"},
+								Optional:             ptr.To(true),
+							},
+						},
 					}},
 				},
 			},
 		},
 	}
 
-	for k, v := range ship.Spec.EnvVars {
-		dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: k, Value: v})
-	}
-
-	for k, v := range ship.Spec.Captain.EnvVars {
-		dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: k, Value: v})
+	if series, ok := seriesVolume(ship); ok {
+		pod := &dep.Spec.Template.Spec
+		pod.Volumes = append(pod.Volumes, series)
+		pod.Containers[0].VolumeMounts = append(pod.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      series.Name,
+			MountPath: shared.SeriesDir,
+			ReadOnly:  true,
+		})
 	}
 
 	err := safeSetControllerReference(ship, dep, r.Scheme)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return dep
+	return dep, nil
 }
 
-func (r *ShipReconciler) serviceForCaptain(ship *freyrv1alpha1.Ship, containerPort int32) *corev1.Service {
+func (r *ShipReconciler) serviceForCaptain(ship *freyrv1alpha1.Ship, containerPort int32) (*corev1.Service, error) {
 	svc := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ship.GetName(),
 			Namespace: ship.GetNamespace(),
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: map[string]string{
-				"app":                          "captain",
-				"app.kubernetes.io/managed-by": "ship-operator",
-				"app.kubernetes.io/owner":      ship.GetName(),
-				"app.kubernetes.io/owner-ns":   ship.GetNamespace(),
-			},
+			Selector: podLabels(ship, "captain"),
 			Ports: []corev1.ServicePort{{
 				Name:     "http",
 				Protocol: "TCP",
@@ -383,25 +598,20 @@ func (r *ShipReconciler) serviceForCaptain(ship *freyrv1alpha1.Ship, containerPo
 
 	err := safeSetControllerReference(ship, svc, r.Scheme)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return svc
+	return svc, nil
 }
 
-func (r *ShipReconciler) deploymentForConscript(ship *freyrv1alpha1.Ship) *appsv1.Deployment {
-	replicas := int32(1)
-	ls := map[string]string{
-		"app":                          "conscript",
-		"app.kubernetes.io/managed-by": "ship-operator",
-		"app.kubernetes.io/owner":      ship.GetName(),
-		"app.kubernetes.io/owner-ns":   ship.GetNamespace(),
-	}
+func (r *ShipReconciler) deploymentForConscript(ship *freyrv1alpha1.Ship, replicas int32) (*appsv1.Deployment, error) {
+	ls := podLabels(ship, "conscript")
 	if ship.Spec.Conscript.Image == "" {
-		ship.Spec.Conscript.Image = "australia-southeast2-docker.pkg.dev/freyr-operator/imgs/conscript:latest"
+		ship.Spec.Conscript.Image = freyrv1alpha1.DefaultConscriptImage
 	}
 
 	dep := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ship.GetName() + "-conscript",
 			Namespace: ship.GetNamespace(),
@@ -432,7 +642,7 @@ func (r *ShipReconciler) deploymentForConscript(ship *freyrv1alpha1.Ship) *appsv
 								corev1.ResourceMemory: resource.MustParse("50Mi"),
 							},
 						},
-						Env: []corev1.EnvVar{
+						Env: envVars([]corev1.EnvVar{
 							{
 								Name: "CAPTAIN_URL",
 								ValueFrom: &corev1.EnvVarSource{
@@ -444,29 +654,25 @@ func (r *ShipReconciler) deploymentForConscript(ship *freyrv1alpha1.Ship) *appsv
 									},
 								},
 							},
-						},
+						}, ship.Spec.EnvVars, ship.Spec.Conscript.EnvVars),
 					}},
 				},
 			},
 		},
 	}
 
-	for k, v := range ship.Spec.EnvVars {
-		dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: k, Value: v})
-	}
-
-	for k, v := range ship.Spec.Conscript.EnvVars {
-		dep.Spec.Template.Spec.Containers[0].Env = append(dep.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: k, Value: v})
-	}
-
 	err := safeSetControllerReference(ship, dep, r.Scheme)
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
-	return dep
+	return dep, nil
 }
 
+// IgnoreReplicasOnlyUpdate drops Deployment updates which only resize it, as
+// the operator made them. Changes to the rest of the spec are let through to
+// be reverted, and changes to the ready or available replicas to refresh the
+// Ship status, as modes whose target never changes don't requeue.
 var IgnoreReplicasOnlyUpdate = predicate.Funcs{
 	UpdateFunc: func(e event.UpdateEvent) bool {
 		oldDep, okOld := e.ObjectOld.(*appsv1.Deployment)
@@ -475,6 +681,10 @@ var IgnoreReplicasOnlyUpdate = predicate.Funcs{
 			// can't determine change, play safe
 			return true
 		}
+		if oldDep.Status.ReadyReplicas != newDep.Status.ReadyReplicas ||
+			oldDep.Status.AvailableReplicas != newDep.Status.AvailableReplicas {
+			return true
+		}
 
 		// Create deep copies and zero out replicas for comparison
 		oldCopy := oldDep.DeepCopy()
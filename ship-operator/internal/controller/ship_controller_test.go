@@ -18,15 +18,24 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
 	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
 )
 
@@ -41,8 +50,10 @@ var _ = Describe("Ship Controller", func() {
 			Namespace: "default", // TODO(user):Modify as needed
 		}
 		ship := &freyrv1alpha1.Ship{}
+		var recorder *record.FakeRecorder
 
 		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(32)
 			By("creating the custom resource for the Kind Ship")
 			err := k8sClient.Get(ctx, typeNamespacedName, ship)
 			if err != nil && errors.IsNotFound(err) {
@@ -51,7 +62,14 @@ var _ = Describe("Ship Controller", func() {
 						Name:      resourceName,
 						Namespace: "default",
 					},
-					// TODO(user): Specify other spec details if needed.
+					Spec: freyrv1alpha1.ShipSpec{
+						Mode: "trig",
+						Trig: freyrv1alpha1.TrigMode{
+							Duration: "300s",
+							Min:      1,
+							Max:      5,
+						},
+					},
 				}
 				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 			}
@@ -65,20 +83,251 @@ var _ = Describe("Ship Controller", func() {
 
 			By("Cleanup the specific resource instance Ship")
 			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+
+			By("Releasing the Ship finalizer")
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Get(ctx, typeNamespacedName, resource)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &ShipReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the Ship status was written")
+			reconciled := &freyrv1alpha1.Ship{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, reconciled)).To(Succeed())
+			Expect(reconciled.Status.Mode).To(Equal("trig"))
+			ready := meta.FindStatusCondition(reconciled.Status.Conditions, freyrv1alpha1.ConditionReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+
+			By("Checking the evaluation was published for the captain")
+			evaluation := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-evaluation", Namespace: "default"}, evaluation)).To(Succeed())
+			Expect(evaluation.Data).To(HaveKeyWithValue(shared.EvaluationFile,
+				fmt.Sprintf(`{"target":%d}`, reconciled.Status.TargetConscripts)))
+
+			By("Checking the resource creation was recorded")
+			Expect(recorder.Events).To(Receive(Equal("Normal Created Created ConfigMap " + resourceName + "-config")))
+		})
+
+		It("should roll spec changes out and revert manual edits", func() {
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
+			reconcileShip := func() {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			captainName := types.NamespacedName{Name: resourceName + "-captain", Namespace: "default"}
+
+			By("Reconciling the created resource")
+			reconcileShip()
+			captain := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, captainName, captain)).To(Succeed())
+			Expect(captain.Spec.Template.Spec.Containers[0].Image).To(Equal(freyrv1alpha1.DefaultCaptainImage))
+
+			By("Changing the captain image on the Ship")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			ship.Spec.Captain.Image = "captain:v2"
+			Expect(k8sClient.Update(ctx, ship)).To(Succeed())
+			reconcileShip()
+			Expect(k8sClient.Get(ctx, captainName, captain)).To(Succeed())
+			Expect(captain.Spec.Template.Spec.Containers[0].Image).To(Equal("captain:v2"))
+
+			By("Editing the captain image on the Deployment by hand")
+			captain.Spec.Template.Spec.Containers[0].Image = "captain:hotfix"
+			Expect(k8sClient.Update(ctx, captain)).To(Succeed())
+			reconcileShip()
+			Expect(k8sClient.Get(ctx, captainName, captain)).To(Succeed())
+			Expect(captain.Spec.Template.Spec.Containers[0].Image).To(Equal("captain:v2"))
+		})
+
+		It("should follow the trig wave on an injected clock", func() {
+			clk := clock.NewFake(time.Unix(1_746_057_600, 0))
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+				Clock:    clk,
+			}
+			conscriptName := types.NamespacedName{Name: resourceName + "-conscript", Namespace: "default"}
+			conscriptsAt := func(offset time.Duration) int32 {
+				clk.Set(time.Unix(1_746_057_600, 0).Add(offset))
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				conscript := &appsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, conscriptName, conscript)).To(Succeed())
+				return *conscript.Spec.Replicas
+			}
+
+			By("Stepping the clock through one 300s period")
+			Expect(conscriptsAt(0)).To(Equal(int32(3)))
+			Expect(conscriptsAt(75 * time.Second)).To(Equal(int32(5)))
+			Expect(conscriptsAt(225 * time.Second)).To(Equal(int32(1)))
+
+			By("Checking the scale was stamped with the injected time")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			Expect(ship.Status.LastScaleTime.Time.Equal(clk.Now())).To(BeTrue())
+
+			By("Checking the coming changes were forecast")
+			Expect(ship.Status.Forecast).NotTo(BeEmpty())
+			Expect(len(ship.Status.Forecast)).To(BeNumerically("<=", maxForecastChanges))
+			Expect(ship.Status.Forecast[0].At.Time.After(clk.Now())).To(BeTrue())
+			Expect(ship.Status.Forecast[0].Replicas).To(Equal(int32(3)))
+		})
+
+		It("should scale up ahead of the trig wave with a lead time", func() {
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			ship.Spec.Scaling.LeadTime = "75s"
+			Expect(k8sClient.Update(ctx, ship)).To(Succeed())
+
+			clk := clock.NewFake(time.Unix(1_746_057_600, 0))
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+				Clock:    clk,
+			}
+			conscriptName := types.NamespacedName{Name: resourceName + "-conscript", Namespace: "default"}
+			conscriptsAt := func(offset time.Duration) int32 {
+				clk.Set(time.Unix(1_746_057_600, 0).Add(offset))
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				conscript := &appsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, conscriptName, conscript)).To(Succeed())
+				return *conscript.Spec.Replicas
+			}
+
+			By("Scaling up to the peak 75s early")
+			Expect(conscriptsAt(0)).To(Equal(int32(5)))
+			By("Holding the peak until the wave falls")
+			Expect(conscriptsAt(75 * time.Second)).To(Equal(int32(5)))
+			Expect(conscriptsAt(150 * time.Second)).To(Equal(int32(3)))
+		})
+
+		It("should replay a series read from a ConfigMap", func() {
+			By("Recording a series which rises to 6 over a minute and falls back")
+			series := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-traffic", Namespace: "default"},
+				Data:       map[string]string{"series.csv": "offset,replicas\n0,2\n60s,6\n120s,2\n"},
+			}
+			Expect(k8sClient.Create(ctx, series)).To(Succeed())
+			DeferCleanup(func() {
+				Expect(k8sClient.Delete(ctx, series)).To(Succeed())
+			})
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			ship.Spec.Mode = "replay"
+			ship.Spec.Replay = freyrv1alpha1.ReplayMode{
+				SeriesConfigMapRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: series.GetName()},
+					Key:                  "series.csv",
+				},
+			}
+			Expect(k8sClient.Update(ctx, ship)).To(Succeed())
+
+			clk := clock.NewFake(time.Unix(1_746_057_600, 0))
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+				Clock:    clk,
 			}
+			conscriptName := types.NamespacedName{Name: resourceName + "-conscript", Namespace: "default"}
+			conscriptsAt := func(offset time.Duration) int32 {
+				clk.Set(time.Unix(1_746_057_600, 0).Add(offset))
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: typeNamespacedName,
+				})
+				Expect(err).NotTo(HaveOccurred())
+				conscript := &appsv1.Deployment{}
+				Expect(k8sClient.Get(ctx, conscriptName, conscript)).To(Succeed())
+				return *conscript.Spec.Replicas
+			}
+
+			By("Interpolating between the points and looping")
+			Expect(conscriptsAt(30 * time.Second)).To(Equal(int32(4)))
+			Expect(conscriptsAt(60 * time.Second)).To(Equal(int32(6)))
+			Expect(conscriptsAt(150 * time.Second)).To(Equal(int32(4)))
+
+			By("Checking the ConfigMap is indexed for the Ship")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			Expect(indexConfigMapRefs(ship)).To(ConsistOf(series.GetName()))
+
+			By("Checking the series is mounted into the captain")
+			captain := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-captain", Namespace: "default"}, captain)).To(Succeed())
+			var projected *corev1.ProjectedVolumeSource
+			for _, v := range captain.Spec.Template.Spec.Volumes {
+				if v.Name == "series" {
+					projected = v.Projected
+				}
+			}
+			Expect(projected).NotTo(BeNil())
+			Expect(projected.Sources).To(HaveLen(1))
+			Expect(projected.Sources[0].ConfigMap.Items).To(ConsistOf(corev1.KeyToPath{Key: "series.csv", Path: shared.ReplaySeriesFile}))
+		})
 
+		It("should orphan owned resources with the Orphan deletion policy", func() {
+			controllerReconciler := &ShipReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Recorder: recorder,
+			}
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: typeNamespacedName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
-			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Deleting the Ship with the Orphan policy")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ship)).To(Succeed())
+			Expect(ship.GetFinalizers()).To(ContainElement(shipFinalizer))
+			ship.Spec.DeletionPolicy = freyrv1alpha1.DeletionPolicyOrphan
+			Expect(k8sClient.Update(ctx, ship)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ship)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking the conscripts were left running without an owner")
+			conscript := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: resourceName + "-conscript", Namespace: "default"}, conscript)).To(Succeed())
+			Expect(conscript.GetOwnerReferences()).To(BeEmpty())
+			Expect(*conscript.Spec.Replicas).NotTo(BeZero())
+
+			By("Recreating the Ship for the cleanup")
+			resource := &freyrv1alpha1.Ship{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       freyrv1alpha1.ShipSpec{Mode: "manual", Replicas: ptr.To(int32(1))},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
 		})
 	})
 })
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+// Reasons for the Events recorded against a Ship.
+const (
+	eventReasonCreated        = "Created"
+	eventReasonConfigRollout  = "ConfigRollout"
+	eventReasonScaled         = "Scaled"
+	eventReasonFallbackScaled = "FallbackScaled"
+	eventReasonModeFailed     = "ModeEvaluationFailed"
+	eventReasonApplyFailed    = "ApplyFailed"
+	eventReasonOrphaned       = "Orphaned"
+	eventReasonDrainTimeout   = "DrainTimeout"
+)
+
+// recordApplied records an Event when an apply created one of the Ship's
+// resources. kind is passed in since the apply response does not keep the
+// object's TypeMeta.
+func (r *ShipReconciler) recordApplied(ship *freyrv1alpha1.Ship, kind string, obj, prev client.Object) {
+	if prev != nil {
+		return
+	}
+	r.Recorder.Eventf(ship, corev1.EventTypeNormal, eventReasonCreated, "Created %s %s", kind, obj.GetName())
+}
+
+// recordConfigRollout records an Event when the Ship ConfigMap changed, which
+// rolls the captain through the config hash annotation.
+func (r *ShipReconciler) recordConfigRollout(ship *freyrv1alpha1.Ship, cm *corev1.ConfigMap, prev client.Object) {
+	prevCM, ok := prev.(*corev1.ConfigMap)
+	if !ok || configHash(prevCM) == configHash(cm) {
+		return
+	}
+	r.Recorder.Eventf(ship, corev1.EventTypeNormal, eventReasonConfigRollout,
+		"Updated ConfigMap %s, rolling out the captain", cm.GetName())
+}
+
+// recordScale records an Event when the conscripts are rescaled, naming the
+// mode which produced the new count.
+func (r *ShipReconciler) recordScale(ship *freyrv1alpha1.Ship, current, target int32, modeErr error) {
+	if current < 0 || current == target {
+		return
+	}
+	if modeErr != nil {
+		r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonFallbackScaled,
+			"Scaled conscripts from %d to %d using the fallback, mode %q failed", current, target, ship.Spec.Mode)
+		return
+	}
+	r.Recorder.Eventf(ship, corev1.EventTypeNormal, eventReasonScaled,
+		"Scaled conscripts from %d to %d (mode %q)", current, target, ship.Spec.Mode)
+}
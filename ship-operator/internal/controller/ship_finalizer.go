@@ -0,0 +1,178 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+const (
+	// shipFinalizer holds a deleted Ship until its resources are torn down
+	// according to spec.deletionPolicy.
+	shipFinalizer = "freyr.fmtl.au/finalizer"
+	// teardownPoll is how often a draining Ship is re-checked. Replica only
+	// updates to the Deployments are filtered out, so they have to be polled.
+	teardownPoll = 5 * time.Second
+	// teardownTimeout stops pods which never terminate from blocking the
+	// deletion of their Ship forever.
+	teardownTimeout = 5 * time.Minute
+)
+
+// ensureFinalizer adds the Ship finalizer if it is missing.
+func (r *ShipReconciler) ensureFinalizer(ctx context.Context, ship *freyrv1alpha1.Ship) error {
+	if controllerutil.ContainsFinalizer(ship, shipFinalizer) {
+		return nil
+	}
+	controllerutil.AddFinalizer(ship, shipFinalizer)
+	return r.Update(ctx, ship)
+}
+
+// finalize tears a deleted Ship down and releases its finalizer once done.
+//
+// With the Delete policy the conscripts are scaled to zero first, then the
+// captain, which has no conscripts left to track by that point. The
+// Deployments, Service and ConfigMaps are garbage collected through their owner
+// references once the finalizer is gone. With the Orphan policy the owner
+// references are stripped instead and everything is left running.
+func (r *ShipReconciler) finalize(ctx context.Context, ship *freyrv1alpha1.Ship) (ctrl.Result, error) {
+	log := ctrllog.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(ship, shipFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	if ship.Spec.DeletionPolicy == freyrv1alpha1.DeletionPolicyOrphan {
+		err := r.orphanResources(ctx, ship)
+		if err != nil {
+			log.Error(err, "Failed to orphan Ship resources")
+			return ctrl.Result{}, err
+		}
+		r.Recorder.Event(ship, corev1.EventTypeNormal, eventReasonOrphaned,
+			"Orphaned the captain, conscripts, Service and ConfigMaps")
+	} else {
+		drained, err := r.drain(ctx, ship)
+		if err != nil {
+			log.Error(err, "Failed to drain Ship")
+			return ctrl.Result{}, err
+		}
+		if !drained {
+			if r.clk().Now().Sub(ship.GetDeletionTimestamp().Time) < teardownTimeout {
+				log.Info("Waiting for Ship to drain")
+				return ctrl.Result{RequeueAfter: teardownPoll}, nil
+			}
+			r.Recorder.Eventf(ship, corev1.EventTypeWarning, eventReasonDrainTimeout,
+				"Ship did not drain within %s, deleting anyway", teardownTimeout)
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ship, shipFinalizer)
+	err := r.Update(ctx, ship)
+	if err != nil {
+		log.Error(err, "Failed to remove Ship finalizer")
+		return ctrl.Result{}, err
+	}
+	r.forgetHistory(client.ObjectKeyFromObject(ship))
+	return ctrl.Result{}, nil
+}
+
+// drain scales the conscripts and then the captain to zero, reporting whether
+// both have finished terminating.
+func (r *ShipReconciler) drain(ctx context.Context, ship *freyrv1alpha1.Ship) (bool, error) {
+	for _, app := range []string{"conscript", "captain"} {
+		done, err := r.scaleToZero(ctx, types.NamespacedName{Name: ship.GetName() + "-" + app, Namespace: ship.GetNamespace()})
+		if err != nil || !done {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// scaleToZero scales the Deployment down and reports whether its pods are
+// gone. A missing Deployment counts as scaled down.
+func (r *ShipReconciler) scaleToZero(ctx context.Context, key types.NamespacedName) (bool, error) {
+	dep := &appsv1.Deployment{}
+	err := r.Get(ctx, key, dep)
+	if errors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if dep.Spec.Replicas == nil || *dep.Spec.Replicas != 0 {
+		orig := dep.DeepCopy()
+		zero := int32(0)
+		dep.Spec.Replicas = &zero
+		err = r.Patch(ctx, dep, client.MergeFrom(orig), client.FieldOwner(fieldManager))
+		if err != nil {
+			return false, err
+		}
+	}
+	return dep.Status.Replicas == 0, nil
+}
+
+// orphanResources strips the Ship's owner reference from everything it owns,
+// so the garbage collector leaves them in place.
+func (r *ShipReconciler) orphanResources(ctx context.Context, ship *freyrv1alpha1.Ship) error {
+	objs := []client.Object{
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: ship.GetName() + "-conscript"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: ship.GetName() + "-captain"}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: ship.GetName()}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ship.GetName() + "-config"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ship.GetName() + "-evaluation"}},
+	}
+	for _, obj := range objs {
+		err := r.Get(ctx, types.NamespacedName{Name: obj.GetName(), Namespace: ship.GetNamespace()}, obj)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		refs := obj.GetOwnerReferences()
+		kept := make([]metav1.OwnerReference, 0, len(refs))
+		for _, ref := range refs {
+			if ref.UID != ship.GetUID() {
+				kept = append(kept, ref)
+			}
+		}
+		if len(kept) == len(refs) {
+			continue
+		}
+		orig, ok := obj.DeepCopyObject().(client.Object)
+		if !ok {
+			continue
+		}
+		obj.SetOwnerReferences(kept)
+		err = r.Patch(ctx, obj, client.MergeFrom(orig))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
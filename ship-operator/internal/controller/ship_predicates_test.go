@@ -0,0 +1,49 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestIgnoreReplicasOnlyUpdate(t *testing.T) {
+	old := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: ptr.To(int32(5))}}
+	tests := []struct {
+		name   string
+		update func(*appsv1.Deployment)
+		want   bool
+	}{
+		{"only the replicas", func(d *appsv1.Deployment) { d.Spec.Replicas = ptr.To(int32(3)) }, false},
+		{"the rest of the spec", func(d *appsv1.Deployment) { d.Spec.Paused = true }, true},
+		{"the ready replicas", func(d *appsv1.Deployment) { d.Status.ReadyReplicas = 2 }, true},
+		{"the available replicas", func(d *appsv1.Deployment) { d.Status.AvailableReplicas = 2 }, true},
+		{"an unrelated status field", func(d *appsv1.Deployment) { d.Status.ObservedGeneration = 4 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			tt.update(updated)
+			if got := IgnoreReplicasOnlyUpdate.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated}); got != tt.want {
+				t.Errorf("Update() after changing %s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
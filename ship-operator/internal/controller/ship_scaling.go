@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/scaling"
+)
+
+const (
+	// modeErrorRequeue retries a failing mode without waiting for a spec change.
+	modeErrorRequeue = 30 * time.Second
+	// policyHoldRequeue re-checks a target held back by stabilization or step
+	// limits, which may be released before the mode's next evaluation.
+	policyHoldRequeue = 15 * time.Second
+	// minRequeue stops a mode asking for a tight loop of reconciles.
+	minRequeue = time.Second
+)
+
+// The Ship status previews the changes forecast over the next day, to the
+// minute.
+const (
+	forecastHorizon    = 24 * time.Hour
+	forecastStep       = time.Minute
+	maxForecastChanges = 10
+)
+
+// forecast predicts the scale changes after now. Modes which can't forecast,
+// or fail to, get no preview rather than failing the reconcile.
+func forecast(ctx context.Context, spec shared.OperatorSpec, clk clock.Clock) []scaling.ForecastPoint {
+	from := clk.Now().Truncate(forecastStep)
+	points, err := scaling.Forecast(ctx, spec, from, from.Add(forecastHorizon), forecastStep, clk)
+	if err != nil {
+		if !errors.Is(err, scaling.ErrNoForecast) {
+			ctrllog.FromContext(ctx).V(1).Info("Failed to forecast scaling mode", "mode", spec.Mode, "error", err.Error())
+		}
+		return nil
+	}
+	// The first point is the current target rather than a change.
+	changes := scaling.Changes(points)
+	if len(changes) > 0 {
+		changes = changes[1:]
+	}
+	return changes[:min(len(changes), maxForecastChanges)]
+}
+
+// lead raises the target to the one forecast spec.scaling.leadTime ahead, so
+// conscripts have started by the time they are needed. A failed forecast
+// keeps the target of now rather than failing the reconcile.
+func lead(ctx context.Context, spec shared.OperatorSpec, target int32, clk clock.Clock) int32 {
+	led, err := scaling.Lead(ctx, spec, target, clk)
+	if err != nil {
+		ctrllog.FromContext(ctx).V(1).Info("Failed to forecast lead target", "mode", spec.Mode, "leadTime", spec.Scaling.LeadTime, "error", err.Error())
+	}
+	return led
+}
+
+// nextEvaluation is when the target may next change. With a lead time that is
+// also leadTime before the mode's next change seen from now+leadTime, so
+// scale ups are picked up early.
+func nextEvaluation(mode scaling.ScalingMode, spec shared.OperatorSpec, clk clock.Clock) time.Time {
+	next := mode.NextEvaluation(spec, clk)
+	leadTime := scaling.LeadTime(spec.Scaling)
+	if _, ok := mode.(scaling.Forecaster); !ok || leadTime == 0 {
+		return next
+	}
+	ahead := mode.NextEvaluation(spec, clock.Offset{Clock: clk, By: leadTime})
+	if ahead.IsZero() {
+		return next
+	}
+	ahead = ahead.Add(-leadTime)
+	if next.IsZero() || ahead.Before(next) {
+		return ahead
+	}
+	return next
+}
+
+// requeueResult schedules the next reconcile for when the mode says its target
+// may change, so Ships don't rely on the cache resync period to be
+// re-evaluated. A zero next evaluation means the target only changes with the
+// spec and no requeue is needed.
+func requeueResult(now, next time.Time, modeFailed, policyHeld bool) ctrl.Result {
+	var after time.Duration
+	if !next.IsZero() {
+		after = max(next.Sub(now), minRequeue)
+	}
+	if modeFailed {
+		after = modeErrorRequeue
+	}
+	if policyHeld && (after == 0 || after > policyHoldRequeue) {
+		after = policyHoldRequeue
+	}
+	return ctrl.Result{RequeueAfter: after}
+}
+
+// applyPolicy runs the Ship's scaling policy over the recommended target. The
+// history behind stabilization and step limits is kept in memory, like the
+// HorizontalPodAutoscaler, so it starts afresh when the operator restarts.
+func (r *ShipReconciler) applyPolicy(key types.NamespacedName, policy shared.ScalingPolicy, current, recommended int32) int32 {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+	if r.histories == nil {
+		r.histories = map[types.NamespacedName]*scaling.History{}
+	}
+	h, ok := r.histories[key]
+	if !ok {
+		h = &scaling.History{}
+		r.histories[key] = h
+	}
+	return scaling.ApplyPolicy(policy, h, r.clk().Now(), current, recommended)
+}
+
+func (r *ShipReconciler) forgetHistory(key types.NamespacedName) {
+	r.historyMu.Lock()
+	defer r.historyMu.Unlock()
+	delete(r.histories, key)
+}
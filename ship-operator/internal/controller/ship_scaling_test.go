@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/scaling"
+)
+
+func TestRequeueResult(t *testing.T) {
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		next       time.Time
+		modeFailed bool
+		policyHeld bool
+		want       time.Duration
+	}{
+		{"a target which only changes with the spec", time.Time{}, false, false, 0},
+		{"the next evaluation", now.Add(40 * time.Second), false, false, 40 * time.Second},
+		{"a next evaluation inside minRequeue", now.Add(100 * time.Millisecond), false, false, minRequeue},
+		{"a next evaluation in the past", now.Add(-time.Minute), false, false, minRequeue},
+		{"a mode failure", now.Add(5 * time.Second), true, false, modeErrorRequeue},
+		{"a mode failure without a next evaluation", time.Time{}, true, false, modeErrorRequeue},
+		{"a policy hold shorter than the next evaluation", now.Add(time.Minute), false, true, policyHoldRequeue},
+		{"a policy hold longer than the next evaluation", now.Add(5 * time.Second), false, true, 5 * time.Second},
+		{"a policy hold without a next evaluation", time.Time{}, false, true, policyHoldRequeue},
+		{"a policy hold on a failing mode", time.Time{}, true, true, policyHoldRequeue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := requeueResult(now, tt.next, tt.modeFailed, tt.policyHeld)
+			if got.RequeueAfter != tt.want {
+				t.Errorf("requeueResult() = %s, want %s", got.RequeueAfter, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextEvaluation(t *testing.T) {
+	now := time.Date(2025, 5, 1, 8, 0, 0, 0, time.UTC)
+	schedule := shared.OperatorSpec{
+		Mode: scaling.ModeSchedule,
+		Schedule: shared.ScheduleMode{Entries: []shared.ScheduleEntry{
+			{Cron: "0 9 * * *", Replicas: 10},
+			{Cron: "0 17 * * *", Replicas: 2},
+		}},
+	}
+	withLead := func(spec shared.OperatorSpec, lead string) shared.OperatorSpec {
+		spec.Scaling.LeadTime = lead
+		return spec
+	}
+	tests := []struct {
+		name string
+		spec shared.OperatorSpec
+		want time.Time
+	}{
+		{"the mode's next change", schedule, now.Add(time.Hour)},
+		{"lead time before the next change", withLead(schedule, "10m"), now.Add(50 * time.Minute)},
+		{"a lead time past the next change", withLead(schedule, "90m"), now.Add(time.Hour)},
+		{"a target which only changes with the spec", withLead(shared.OperatorSpec{Mode: scaling.ModeManual}, "10m"), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := scaling.Lookup(tt.spec.Mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := nextEvaluation(mode, tt.spec, clock.NewFake(now)); !got.Equal(tt.want) {
+				t.Errorf("nextEvaluation() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
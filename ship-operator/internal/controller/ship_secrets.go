@@ -0,0 +1,170 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/socialviolation/freyr/shared"
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+// secretRefIndex indexes Ships by the names of the Secrets they reference, such
+// as their OpenWeather API key, so a rotated Secret can be mapped back to its
+// Ships.
+const secretRefIndex = ".spec.secretRefs"
+
+func indexSecretRefs(obj client.Object) []string {
+	ship, ok := obj.(*freyrv1alpha1.Ship)
+	if !ok {
+		return nil
+	}
+	names := secretRefNames(nil, ship.Spec.Weather, ship.Spec.External)
+	for _, child := range ship.Spec.Composite.Children {
+		names = secretRefNames(names, child.Weather, child.External)
+	}
+	return names
+}
+
+func secretRefNames(names []string, weather freyrv1alpha1.WeatherMode, external freyrv1alpha1.ExternalMode) []string {
+	if ref := weather.APIKeySecretRef; ref != nil {
+		names = append(names, ref.Name)
+	}
+	if tls := external.TLS; tls != nil {
+		if tls.CASecretRef != nil {
+			names = append(names, tls.CASecretRef.Name)
+		}
+		if tls.CertSecretRef != nil {
+			names = append(names, tls.CertSecretRef.Name)
+		}
+	}
+	return names
+}
+
+// shipsForSecret enqueues every Ship in the Secret's namespace which
+// references that Secret.
+func (r *ShipReconciler) shipsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	ships := &freyrv1alpha1.ShipList{}
+	err := r.List(ctx, ships, client.InNamespace(obj.GetNamespace()), client.MatchingFields{secretRefIndex: obj.GetName()})
+	if err != nil {
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(ships.Items))
+	for _, ship := range ships.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: ship.GetName(), Namespace: ship.GetNamespace()}})
+	}
+	return reqs
+}
+
+// resolveSecretRefs copies the values of any Secret references in the Ship
+// into the inline fields of the operator spec handed to the scaling mode.
+func (r *ShipReconciler) resolveSecretRefs(ctx context.Context, ship *freyrv1alpha1.Ship, spec *shared.OperatorSpec) error {
+	err := r.resolveModeSecrets(ctx, ship, "", ship.Spec.Weather, ship.Spec.External, &spec.Weather, &spec.External)
+	if err != nil {
+		return err
+	}
+	for i, child := range ship.Spec.Composite.Children {
+		if i >= len(spec.Composite.Children) {
+			break
+		}
+		dst := &spec.Composite.Children[i]
+		prefix := fmt.Sprintf("composite.children[%d].", i)
+		err = r.resolveModeSecrets(ctx, ship, prefix, child.Weather, child.External, &dst.Weather, &dst.External)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveModeSecrets resolves the Secret references of one set of mode blocks,
+// either the Ship's own or a composite child's. prefix locates the blocks in
+// error messages.
+func (r *ShipReconciler) resolveModeSecrets(ctx context.Context, ship *freyrv1alpha1.Ship, prefix string,
+	weather freyrv1alpha1.WeatherMode, external freyrv1alpha1.ExternalMode,
+	weatherSpec *shared.WeatherMode, externalSpec *shared.ExternalMode) error {
+	if ref := weather.APIKeySecretRef; ref != nil {
+		key, err := r.secretKey(ctx, ship, ref, prefix+"weather.apiKeySecretRef")
+		if err != nil {
+			return err
+		}
+		if key != nil {
+			weatherSpec.APIKey = string(key)
+		}
+	}
+
+	tls := external.TLS
+	if tls == nil || externalSpec.TLS == nil {
+		return nil
+	}
+	if tls.CASecretRef != nil {
+		ca, err := r.secretKey(ctx, ship, tls.CASecretRef, prefix+"external.tls.caSecretRef")
+		if err != nil {
+			return err
+		}
+		externalSpec.TLS.CA = ca
+	}
+	if tls.CertSecretRef != nil {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, types.NamespacedName{Name: tls.CertSecretRef.Name, Namespace: ship.GetNamespace()}, secret)
+		if err != nil {
+			return fmt.Errorf("failed to read %sexternal.tls.certSecretRef: %w", prefix, err)
+		}
+		externalSpec.TLS.Cert = secret.Data[corev1.TLSCertKey]
+		externalSpec.TLS.Key = secret.Data[corev1.TLSPrivateKeyKey]
+	}
+	return nil
+}
+
+// secretKey reads the key selected by ref from a Secret in the Ship's
+// namespace. A missing optional Secret or key returns nil.
+func (r *ShipReconciler) secretKey(ctx context.Context, ship *freyrv1alpha1.Ship, ref *corev1.SecretKeySelector, field string) ([]byte, error) {
+	optional := ref.Optional != nil && *ref.Optional
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ship.GetNamespace()}, secret)
+	if err != nil {
+		if errors.IsNotFound(err) && optional {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", field, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok && !optional {
+		return nil, fmt.Errorf("secret %s has no key %q for %s", ref.Name, ref.Key, field)
+	}
+	return value, nil
+}
+
+// redactedSpec returns a copy of the Ship spec which is safe to publish in the
+// Ship's ConfigMap.
+func redactedSpec(ship *freyrv1alpha1.Ship) *freyrv1alpha1.ShipSpec {
+	spec := ship.Spec.DeepCopy()
+	spec.Weather.APIKey = ""
+	for i := range spec.Composite.Children {
+		spec.Composite.Children[i].Weather.APIKey = ""
+	}
+	return spec
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/socialviolation/freyr/shared/scaling"
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+// patchStatus writes the Ship status through the status subresource. The patch
+// is computed against orig so an unchanged status does not bump the
+// resourceVersion and trigger another reconcile.
+func (r *ShipReconciler) patchStatus(ctx context.Context, ship, orig *freyrv1alpha1.Ship) error {
+	return r.Status().Patch(ctx, ship, client.MergeFrom(orig))
+}
+
+func setCondition(ship *freyrv1alpha1.Ship, condType string, status metav1.ConditionStatus, reason, msg string) {
+	meta.SetStatusCondition(&ship.Status.Conditions, metav1.Condition{
+		Type:               condType,
+		Status:             status,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: ship.GetGeneration(),
+	})
+}
+
+// setShipConditions records the observed conscript count and derives every
+// status condition from the current state of the owned Deployments.
+func setShipConditions(ship *freyrv1alpha1.Ship, captainDep, conscriptDep *appsv1.Deployment, target int32, modeErr error) {
+	ship.Status.TargetConscripts = target
+	ship.Status.ObservedConscripts = conscriptDep.Status.ReadyReplicas
+	ship.Status.Replicas = conscriptDep.Status.Replicas
+	ship.Status.Selector = metav1.FormatLabelSelector(conscriptDep.Spec.Selector)
+
+	if captainDep.Status.AvailableReplicas > 0 {
+		setCondition(ship, freyrv1alpha1.ConditionCaptainAvailable, metav1.ConditionTrue, "DeploymentAvailable",
+			fmt.Sprintf("%d captain replica(s) available", captainDep.Status.AvailableReplicas))
+	} else {
+		setCondition(ship, freyrv1alpha1.ConditionCaptainAvailable, metav1.ConditionFalse, "DeploymentUnavailable",
+			"No captain replicas are available")
+	}
+
+	if modeErr != nil {
+		setCondition(ship, freyrv1alpha1.ConditionModeHealthy, metav1.ConditionFalse, "EvaluationFailed", modeErr.Error())
+	} else {
+		setCondition(ship, freyrv1alpha1.ConditionModeHealthy, metav1.ConditionTrue, "Evaluated",
+			fmt.Sprintf("Mode %q computed a target of %d", ship.Spec.Mode, target))
+	}
+
+	if conscriptDep.Status.ReadyReplicas == target {
+		setCondition(ship, freyrv1alpha1.ConditionConscriptsScaled, metav1.ConditionTrue, "Scaled",
+			fmt.Sprintf("%d/%d conscripts ready", conscriptDep.Status.ReadyReplicas, target))
+	} else {
+		setCondition(ship, freyrv1alpha1.ConditionConscriptsScaled, metav1.ConditionFalse, "Scaling",
+			fmt.Sprintf("%d/%d conscripts ready", conscriptDep.Status.ReadyReplicas, target))
+	}
+
+	for _, t := range []string{freyrv1alpha1.ConditionCaptainAvailable, freyrv1alpha1.ConditionModeHealthy, freyrv1alpha1.ConditionConscriptsScaled} {
+		if !meta.IsStatusConditionTrue(ship.Status.Conditions, t) {
+			setCondition(ship, freyrv1alpha1.ConditionReady, metav1.ConditionFalse, "NotReady",
+				fmt.Sprintf("Condition %s is not True", t))
+			return
+		}
+	}
+	setCondition(ship, freyrv1alpha1.ConditionReady, metav1.ConditionTrue, "Ready", "Ship is ready")
+}
+
+// contributionStatus converts the contributions of a combined mode for the
+// Ship status.
+func contributionStatus(contributions []scaling.Contribution) []freyrv1alpha1.ModeContribution {
+	if len(contributions) == 0 {
+		return nil
+	}
+	status := make([]freyrv1alpha1.ModeContribution, 0, len(contributions))
+	for _, c := range contributions {
+		mc := freyrv1alpha1.ModeContribution{Name: c.Name, Mode: c.Mode, Target: c.Target}
+		if c.Err != nil {
+			mc.Error = c.Err.Error()
+		}
+		status = append(status, mc)
+	}
+	return status
+}
+
+// forecastStatus converts the forecast changes for the Ship status.
+func forecastStatus(points []scaling.ForecastPoint) []freyrv1alpha1.ForecastChange {
+	if len(points) == 0 {
+		return nil
+	}
+	status := make([]freyrv1alpha1.ForecastChange, 0, len(points))
+	for _, p := range points {
+		status = append(status, freyrv1alpha1.ForecastChange{At: metav1.NewTime(p.At), Replicas: p.Target})
+	}
+	return status
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/scaling"
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+// nolint:unused
+// log is for logging in this package.
+var shiplog = logf.Log.WithName("ship-resource")
+
+// SetupShipWebhookWithManager registers the webhook for Ship in the manager.
+func SetupShipWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&freyrv1alpha1.Ship{}).
+		WithValidator(&ShipCustomValidator{}).
+		WithDefaulter(&ShipCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-freyr-fmtl-au-v1alpha1-ship,mutating=true,failurePolicy=fail,sideEffects=None,groups=freyr.fmtl.au,resources=ships,verbs=create;update,versions=v1alpha1,name=mship-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ShipCustomDefaulter sets default values on the Ship resource when it is
+// created or updated.
+type ShipCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &ShipCustomDefaulter{}
+
+// Default fills in the mode, the captain and conscript images and any fields
+// the selected mode knows how to default.
+func (d *ShipCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	ship, ok := obj.(*freyrv1alpha1.Ship)
+	if !ok {
+		return fmt.Errorf("expected a Ship object but got %T", obj)
+	}
+	shiplog.Info("Defaulting for Ship", "name", ship.GetName())
+
+	if ship.Spec.Mode == "" {
+		ship.Spec.Mode = scaling.ModeTrig
+		if ship.Spec.Weather.City != "" || ship.Spec.Weather.Provider != "" {
+			ship.Spec.Mode = scaling.ModeWeather
+		}
+	}
+	if ship.Spec.Captain.Image == "" {
+		ship.Spec.Captain.Image = freyrv1alpha1.DefaultCaptainImage
+	}
+	if ship.Spec.Conscript.Image == "" {
+		ship.Spec.Conscript.Image = freyrv1alpha1.DefaultConscriptImage
+	}
+
+	mode, err := scaling.Lookup(ship.Spec.Mode)
+	if err != nil {
+		// Unknown modes are rejected by the validating webhook.
+		return nil
+	}
+	defaulter, ok := mode.(scaling.Defaulter)
+	if !ok {
+		return nil
+	}
+
+	spec, err := operatorSpec(ship)
+	if err != nil {
+		return err
+	}
+	defaulter.Default(&spec)
+
+	// The operator spec shares its JSON shape with ShipSpec, so decoding it
+	// over the Ship only touches the fields the mode owns.
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, &ship.Spec)
+}
+
+// +kubebuilder:webhook:path=/validate-freyr-fmtl-au-v1alpha1-ship,mutating=false,failurePolicy=fail,sideEffects=None,groups=freyr.fmtl.au,resources=ships,verbs=create;update,versions=v1alpha1,name=vship-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ShipCustomValidator rejects Ships whose mode is unknown or whose mode block
+// would not produce a usable target.
+type ShipCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ShipCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Ship.
+func (v *ShipCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ship, ok := obj.(*freyrv1alpha1.Ship)
+	if !ok {
+		return nil, fmt.Errorf("expected a Ship object but got %T", obj)
+	}
+	shiplog.Info("Validation for Ship upon creation", "name", ship.GetName())
+
+	return shipWarnings(ship), validateShip(ship)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Ship.
+func (v *ShipCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	ship, ok := newObj.(*freyrv1alpha1.Ship)
+	if !ok {
+		return nil, fmt.Errorf("expected a Ship object for the newObj but got %T", newObj)
+	}
+	shiplog.Info("Validation for Ship upon update", "name", ship.GetName())
+
+	return shipWarnings(ship), validateShip(ship)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Ship.
+func (v *ShipCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateShip(ship *freyrv1alpha1.Ship) error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	mode, err := scaling.Lookup(ship.Spec.Mode)
+	if err != nil {
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("mode"), ship.Spec.Mode, scaling.Modes()))
+	} else {
+		spec, err := operatorSpec(ship)
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(specPath, err))
+		} else if err := mode.Validate(spec); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child(mode.Name()), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	spec, err := operatorSpec(ship)
+	if err == nil {
+		if err := scaling.ValidatePolicy(spec.Scaling); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("scaling"), field.OmitValueType{}, err.Error()))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(freyrv1alpha1.GroupVersion.WithKind("Ship").GroupKind(), ship.GetName(), allErrs)
+}
+
+func shipWarnings(ship *freyrv1alpha1.Ship) admission.Warnings {
+	var warnings admission.Warnings
+	if ship.Spec.Weather.APIKey != "" {
+		warnings = append(warnings, "spec.weather.apiKey is deprecated and readable by anyone who can read this Ship, use spec.weather.apiKeySecretRef")
+	}
+	for i, child := range ship.Spec.Composite.Children {
+		if child.Weather.APIKey != "" {
+			warnings = append(warnings, fmt.Sprintf("spec.composite.children[%d].weather.apiKey is deprecated and readable by anyone who can read this Ship, use apiKeySecretRef", i))
+		}
+	}
+	if ship.Spec.Replicas != nil && ship.Spec.Mode != scaling.ModeManual {
+		warnings = append(warnings, "spec.replicas is only honoured in manual mode")
+	}
+	return warnings
+}
+
+// operatorSpec converts the Ship spec into the shared.OperatorSpec understood
+// by the scaling modes.
+func operatorSpec(ship *freyrv1alpha1.Ship) (shared.OperatorSpec, error) {
+	spec := shared.OperatorSpec{}
+	b, err := json.Marshal(ship.Spec)
+	if err != nil {
+		return spec, err
+	}
+	err = json.Unmarshal(b, &spec)
+	return spec, err
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	freyrv1alpha1 "github.com/socialviolation/freyr/ship-operator/api/v1alpha1"
+)
+
+var _ = Describe("Ship Webhook", func() {
+	var (
+		ctx       context.Context
+		obj       *freyrv1alpha1.Ship
+		validator ShipCustomValidator
+		defaulter ShipCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		obj = &freyrv1alpha1.Ship{
+			ObjectMeta: metav1.ObjectMeta{Name: "black-pearl", Namespace: "default"},
+		}
+		validator = ShipCustomValidator{}
+		defaulter = ShipCustomDefaulter{}
+	})
+
+	Context("When creating Ship under Defaulting Webhook", func() {
+		It("Should default an empty Ship to a valid trig Ship", func() {
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Mode).To(Equal("trig"))
+			Expect(obj.Spec.Trig.Duration).To(Equal("300s"))
+			Expect(obj.Spec.Trig.Min).To(Equal(int32(1)))
+			Expect(obj.Spec.Trig.Max).To(Equal(int32(6)))
+			Expect(obj.Spec.Captain.Image).To(Equal(freyrv1alpha1.DefaultCaptainImage))
+			Expect(obj.Spec.Conscript.Image).To(Equal(freyrv1alpha1.DefaultConscriptImage))
+
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should pick weather mode when a city is configured", func() {
+			obj.Spec.Weather.City = "Melbourne"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Mode).To(Equal("weather"))
+		})
+
+		It("Should default weather mode to the temperature as is", func() {
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{City: "Melbourne", Country: "AU"}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Weather.Signal).To(Equal("temp"))
+			Expect(obj.Spec.Weather.Mapping.Type).To(Equal("direct"))
+		})
+
+		It("Should default manual mode to a single replica", func() {
+			obj.Spec.Mode = "manual"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Replicas).NotTo(BeNil())
+			Expect(*obj.Spec.Replicas).To(Equal(int32(1)))
+		})
+
+		It("Should keep explicitly set trig bounds", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "10m", Min: 3, Max: 9}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Trig).To(Equal(freyrv1alpha1.TrigMode{Duration: "10m", Min: 3, Max: 9}))
+		})
+		It("Should default the composite aggregation and its children", func() {
+			obj.Spec.Mode = "composite"
+			obj.Spec.Composite.Children = []freyrv1alpha1.CompositeChild{{Mode: "trig"}}
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Composite.Aggregation).To(Equal("max"))
+			Expect(obj.Spec.Composite.Children[0].Trig.Duration).NotTo(BeEmpty())
+		})
+	})
+
+	Context("When creating or updating Ship under Validating Webhook", func() {
+		It("Should deny an unknown mode", func() {
+			obj.Spec.Mode = "tidal"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.mode"))
+		})
+
+		It("Should deny manual mode without replicas", func() {
+			obj.Spec.Mode = "manual"
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should warn when replicas are set outside of manual mode", func() {
+			replicas := int32(4)
+			obj.Spec.Mode = "trig"
+			obj.Spec.Replicas = &replicas
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "300s", Min: 1, Max: 5}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(ContainElement(ContainSubstring("manual mode")))
+		})
+
+		It("Should deny an unparsable trig duration", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "five minutes", Min: 1, Max: 5}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("trig.duration"))
+		})
+
+		It("Should deny a trig amplitude above the full range", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "10m", Min: 1, Max: 5, Waveform: "triangle", Amplitude: "1.5"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("trig.amplitude"))
+		})
+
+		It("Should admit a square trig wave with a duty cycle and phase", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "1h", Min: 1, Max: 5, Waveform: "square", DutyCycle: 25, Phase: "-15m"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a trig wave anchored in an unknown timezone", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "24h", Min: 1, Max: 5, Timezone: "Australia/Atlantis"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("trig.timezone"))
+		})
+
+		It("Should admit a weekly trig wave anchored to Monday morning", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{
+				Duration: "168h",
+				Min:      1,
+				Max:      5,
+				Anchor:   "2025-01-06T09:00:00",
+				Timezone: "Australia/Melbourne",
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny trig bounds where min is greater than max", func() {
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "300s", Min: 8, Max: 2}
+			_, err := validator.ValidateUpdate(ctx, obj.DeepCopy(), obj)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Should deny a scaling policy whose min is above its max", func() {
+			minReplicas, maxReplicas := int32(6), int32(2)
+			obj.Spec.Mode = "trig"
+			obj.Spec.Trig = freyrv1alpha1.TrigMode{Duration: "300s", Min: 1, Max: 5}
+			obj.Spec.Scaling = freyrv1alpha1.ScalingPolicy{MinReplicas: &minReplicas, MaxReplicas: &maxReplicas}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.scaling"))
+		})
+
+		It("Should deny a schedule with an unparsable cron expression", func() {
+			obj.Spec.Mode = "schedule"
+			obj.Spec.Schedule = freyrv1alpha1.ScheduleMode{
+				Entries: []freyrv1alpha1.ScheduleEntry{{Cron: "0 9 * * 1-5", Replicas: 10}, {Cron: "at five", Replicas: 2}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("schedule.entries[1].cron"))
+		})
+
+		It("Should admit a schedule with a timezone", func() {
+			obj.Spec.Mode = "schedule"
+			obj.Spec.Schedule = freyrv1alpha1.ScheduleMode{
+				Timezone: "Australia/Melbourne",
+				Entries: []freyrv1alpha1.ScheduleEntry{
+					{Name: "business hours", Cron: "0 9 * * 1-5", Replicas: 10},
+					{Name: "nights", Cron: "0 17 * * 1-5", Replicas: 2},
+					{Name: "weekends", Cron: "0 0 * * 6", Replicas: 1},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny an external scaler address without a port", func() {
+			obj.Spec.Mode = "external"
+			obj.Spec.External = freyrv1alpha1.ExternalMode{Address: "trigscaler.freyr"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("external.address"))
+		})
+
+		It("Should deny replay mode without a series ConfigMap", func() {
+			obj.Spec.Mode = "replay"
+			obj.Spec.Replay = freyrv1alpha1.ReplayMode{End: "loop"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("replay.seriesConfigMapRef"))
+		})
+
+		It("Should admit a clamped replay of a JSON series", func() {
+			obj.Spec.Mode = "replay"
+			obj.Spec.Replay = freyrv1alpha1.ReplayMode{
+				SeriesConfigMapRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "traffic"},
+					Key:                  "black-friday.json",
+				},
+				End:           "clamp",
+				Interpolation: "step",
+				Anchor:        "2025-11-28T00:00:00",
+				Timezone:      "Australia/Melbourne",
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a composite nested in a composite", func() {
+			obj.Spec.Mode = "composite"
+			obj.Spec.Composite = freyrv1alpha1.CompositeMode{
+				Aggregation: "max",
+				Children: []freyrv1alpha1.CompositeChild{
+					{Name: "wave", Mode: "trig", Trig: freyrv1alpha1.TrigMode{Duration: "10m", Min: 1, Max: 5}},
+					{Name: "inner", Mode: "composite"},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("composite.children[1].mode"))
+		})
+
+		It("Should deny a composite child with an invalid block", func() {
+			obj.Spec.Mode = "composite"
+			obj.Spec.Composite = freyrv1alpha1.CompositeMode{
+				Aggregation: "sum",
+				Children: []freyrv1alpha1.CompositeChild{
+					{Name: "wave", Mode: "trig", Trig: freyrv1alpha1.TrigMode{Duration: "soon", Min: 1, Max: 5}},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("composite.children[0].trig.duration"))
+		})
+
+		It("Should admit the max of a trig wave and a manual floor", func() {
+			obj.Spec.Mode = "composite"
+			obj.Spec.Composite = freyrv1alpha1.CompositeMode{
+				Aggregation: "max",
+				Children: []freyrv1alpha1.CompositeChild{
+					{Name: "wave", Mode: "trig", Trig: freyrv1alpha1.TrigMode{Duration: "10m", Min: 1, Max: 5}},
+					{Name: "floor", Mode: "manual", Replicas: ptr.To[int32](2)},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny weather mode without a city", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{Country: "AU"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("weather.city"))
+		})
+
+		It("Should deny a linear weather mapping with an inverted input range", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{
+				City:    "Melbourne",
+				Country: "AU",
+				APIKey:  "xxx",
+				Mapping: freyrv1alpha1.WeatherMapping{Type: "linear", InputMin: "30", InputMax: "-5", OutputMax: 10},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("weather.mapping.inputMin"))
+		})
+
+		It("Should admit a weather Ship stepping through wind speed thresholds", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{
+				City:    "Wellington",
+				Country: "NZ",
+				APIKeySecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "openweather"},
+					Key:                  "apiKey",
+				},
+				Signal: "wind",
+				Mapping: freyrv1alpha1.WeatherMapping{
+					Type:      "thresholds",
+					OutputMin: 1,
+					Thresholds: []freyrv1alpha1.WeatherThreshold{
+						{Above: "5", Replicas: 3},
+						{Above: "12.5", Replicas: 8},
+					},
+				},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should admit an Open-Meteo weather Ship without an API key", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{Provider: "openmeteo", City: "Melbourne", Country: "AU"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should deny a fake weather provider without a script url", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{Provider: "fake"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("weather.url"))
+			Expect(err.Error()).NotTo(ContainSubstring("weather.city"))
+		})
+
+		It("Should admit a weather Ship with an inline key but warn about it", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{City: "Melbourne", Country: "AU", APIKey: "xxx"}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(HaveLen(1))
+		})
+
+		It("Should admit a weather Ship which reads its key from a Secret", func() {
+			obj.Spec.Mode = "weather"
+			obj.Spec.Weather = freyrv1alpha1.WeatherMode{
+				City:    "Melbourne",
+				Country: "AU",
+				APIKeySecretRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "openweather"},
+					Key:                  "apiKey",
+				},
+			}
+			warnings, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(warnings).To(BeEmpty())
+		})
+	})
+})
@@ -5,10 +5,16 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io/fs"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -20,6 +26,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/scaling"
+	"github.com/socialviolation/freyr/shared/schedule"
 	"github.com/socialviolation/freyr/shared/trig"
 )
 
@@ -28,10 +37,40 @@ type Conscript struct {
 	LastSeen time.Time `json:"last_seen"`
 }
 
+// upcomingScheduleChanges is how many future schedule entries the docket lists.
+const upcomingScheduleChanges = 10
+
+// maxChartSize bounds the width and height of charts served by the captain.
+const maxChartSize = 4096
+
+// Forecasts default to the next day in 5m steps, and look at most a week ahead.
+const (
+	defaultForecastHours = 24
+	maxForecastHours     = 7 * 24
+	defaultForecastStep  = 5 * time.Minute
+	// docketForecastChanges is how many forecast changes the docket lists.
+	docketForecastChanges = 10
+)
+
 type CaptainController struct {
 	cycleStaleDuration time.Duration
 	conscripts         map[string]Conscript
 	opSpec             shared.OperatorSpec
+	clock              clock.Clock
+	// evaluationPath is the operator's evaluation mounted from its ConfigMap.
+	evaluationPath string
+	// seriesDir holds the replay series mounted from the Ship's ConfigMaps.
+	seriesDir string
+	// wave is the trig block parsed once, the spec doesn't change while the
+	// captain runs.
+	wave    trig.Wave
+	waveErr error
+
+	// preview caches the docket's forecast for the minute it was made in, as
+	// the page reloads every 2s.
+	previewMu sync.Mutex
+	previewAt time.Time
+	preview   []scaling.ForecastPoint
 
 	docketTmpl *template.Template
 	metric     *captainMetrics
@@ -56,24 +95,34 @@ func NewCaptainController() (*CaptainController, error) {
 		"formatTime": func(t time.Time) string {
 			return t.Format("15:04:05")
 		},
+		"formatDateTime": func(t time.Time) string {
+			return t.Format("Mon 02 Jan 15:04 MST")
+		},
 	}
 
 	cc := &CaptainController{
 		cycleStaleDuration: time.Second * 3,
 		conscripts:         make(map[string]Conscript),
 		opSpec:             spec,
+		clock:              clock.Real{},
+		evaluationPath:     filepath.Join(shared.EvaluationDir, shared.EvaluationFile),
+		seriesDir:          shared.SeriesDir,
 		docketTmpl:         template.Must(template.New("docket").Funcs(funcMap).Parse(docketTemplate)),
 	}
 
+	args, err := trig.FromSpec(spec.Trig)
+	if err == nil {
+		cc.wave, err = trig.NewWave(args)
+	}
+	cc.waveErr = err
+
 	cc.metric, _ = newCaptainMetrics(func(ctx context.Context, observer metric.Int64Observer) error {
-		args := trig.Args{
-			Min:      cc.opSpec.Trig.Min,
-			Max:      cc.opSpec.Trig.Max,
-			Duration: cc.opSpec.Trig.Duration,
+		target, err := cc.target()
+		if err != nil {
+			return err
 		}
-		target, _ := trig.GetValue(args)
 		observer.Observe(int64(target))
-		log.Info().Msgf("target observable %d", int(target))
+		log.Info().Msgf("target observable %d", target)
 		return nil
 	}, func(ctx context.Context, observer metric.Int64Observer) error {
 		observer.Observe(int64(len(cc.conscripts)))
@@ -89,6 +138,8 @@ func (c *CaptainController) Serve(ctx context.Context, r *gin.Engine, middleware
 	r.GET("/", c.docketHtml)
 	r.GET("/enlist", c.enlist)
 	r.GET("/conscripts", c.docket)
+	r.GET("/chart", c.chart)
+	r.GET("/forecast", c.forecast)
 
 	c.routinePurger(ctx)
 }
@@ -114,18 +165,22 @@ func (c *CaptainController) enlist(g *gin.Context) {
 
 	c.conscripts[g.Request.RemoteAddr] = Conscript{
 		IP:       g.Request.RemoteAddr,
-		LastSeen: time.Now(),
+		LastSeen: c.clock.Now(),
 	}
 }
 
 type docketResponse struct {
-	Spec       shared.OperatorSpec  `json:"operator,omitempty"`
-	Name       string               `json:"name,omitempty"`
-	Namespace  string               `json:"namespace,omitempty"`
-	Conscripts map[string]time.Time `json:"conscripts"`
-	Trig       string               `json:"trig,omitempty"`
-	Target     int                  `json:"target,omitempty"`
-	Actual     int                  `json:"actual"`
+	Spec          shared.OperatorSpec     `json:"operator,omitempty"`
+	Name          string                  `json:"name,omitempty"`
+	Namespace     string                  `json:"namespace,omitempty"`
+	Conscripts    map[string]time.Time    `json:"conscripts"`
+	Chart         template.HTML           `json:"-"`
+	Active        *schedule.Change        `json:"active,omitempty"`
+	Schedule      []schedule.Change       `json:"schedule,omitempty"`
+	Contributions []shared.Contribution   `json:"contributions,omitempty"`
+	Forecast      []scaling.ForecastPoint `json:"forecast,omitempty"`
+	Target        int                     `json:"target,omitempty"`
+	Actual        int                     `json:"actual"`
 }
 
 type errorResponse struct {
@@ -133,20 +188,17 @@ type errorResponse struct {
 }
 
 func (c *CaptainController) docket(ctx *gin.Context) {
-	target, err := trig.GetValue(trig.Args{
-		Min:      c.opSpec.Trig.Min,
-		Max:      c.opSpec.Trig.Max,
-		Duration: c.opSpec.Trig.Duration,
-	})
+	target, err := c.target()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse{Message: "error calculating the target conscripts"})
+		ctx.JSON(http.StatusInternalServerError, errorResponse{Message: fmt.Errorf("error calculating the target conscripts: %w", err).Error()})
+		return
 	}
 
 	dr := docketResponse{
 		Spec:       c.opSpec,
 		Name:       os.Getenv("NAME"),
-		Namespace:  os.Getenv("NAME"),
-		Target:     int(target),
+		Namespace:  os.Getenv("NAMESPACE"),
+		Target:     target,
 		Actual:     len(c.conscripts),
 		Conscripts: make(map[string]time.Time),
 	}
@@ -157,6 +209,154 @@ func (c *CaptainController) docket(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, dr)
 }
 
+// target follows the wave live in trig mode. Other modes report the
+// operator's last evaluation, as the captain can't read the Secrets some of
+// them need.
+func (c *CaptainController) target() (int, error) {
+	if c.opSpec.Mode == "trig" {
+		if c.waveErr != nil {
+			return 0, c.waveErr
+		}
+		return int(c.wave.Value(c.clock.Now())), nil
+	}
+	e, _ := c.evaluation()
+	return int(e.Target), nil
+}
+
+// chart draws the trig wave. The query picks the format (ascii, svg, png or
+// json, defaulting to svg), the width and height, and the window around now,
+// e.g. /chart?format=png&width=1200&window=24h.
+func (c *CaptainController) chart(ctx *gin.Context) {
+	if c.opSpec.Mode != "trig" {
+		ctx.JSON(http.StatusNotFound, errorResponse{Message: fmt.Sprintf("no chart for mode %q", c.opSpec.Mode)})
+		return
+	}
+	if c.waveErr != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse{Message: c.waveErr.Error()})
+		return
+	}
+
+	var err error
+	opts := trig.ChartOptions{}
+	for key, dst := range map[string]*int{"width": &opts.Width, "height": &opts.Height} {
+		if v := ctx.Query(key); v != "" {
+			*dst, err = strconv.Atoi(v)
+			if err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse{Message: fmt.Sprintf("%s: %v", key, err)})
+				return
+			}
+		}
+	}
+	if v := ctx.Query("window"); v != "" {
+		opts.Window, err = time.ParseDuration(v)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse{Message: fmt.Sprintf("window: %v", err)})
+			return
+		}
+	}
+	if opts.Width > maxChartSize || opts.Height > maxChartSize {
+		ctx.JSON(http.StatusBadRequest, errorResponse{Message: fmt.Sprintf("charts are at most %dx%d", maxChartSize, maxChartSize)})
+		return
+	}
+
+	renderer, err := trig.RendererFor(ctx.DefaultQuery("format", trig.FormatSVG))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse{Message: err.Error()})
+		return
+	}
+	chart, err := trig.NewChart(c.wave, c.clock.Now(), renderer, opts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse{Message: err.Error()})
+		return
+	}
+	buf := &bytes.Buffer{}
+	err = renderer.Render(buf, chart)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse{Message: fmt.Errorf("error rendering chart: %w", err).Error()})
+		return
+	}
+	ctx.Data(http.StatusOK, renderer.ContentType(), buf.Bytes())
+}
+
+type forecastResponse struct {
+	Mode    string                  `json:"mode"`
+	From    time.Time               `json:"from"`
+	To      time.Time               `json:"to"`
+	Step    string                  `json:"step"`
+	Points  []scaling.ForecastPoint `json:"points"`
+	Changes []scaling.ForecastPoint `json:"changes"`
+}
+
+// forecast predicts the targets of the mode, e.g. /forecast?hours=48&step=15m.
+// Modes which read live metrics can't forecast and answer 422, and failures
+// of the mode, such as its weather provider being down, answer 502.
+func (c *CaptainController) forecast(ctx *gin.Context) {
+	hours := defaultForecastHours
+	if v := ctx.Query("hours"); v != "" {
+		var err error
+		hours, err = strconv.Atoi(v)
+		if err != nil || hours < 1 || hours > maxForecastHours {
+			ctx.JSON(http.StatusBadRequest, errorResponse{Message: fmt.Sprintf("hours: must be between 1 and %d", maxForecastHours)})
+			return
+		}
+	}
+	step := defaultForecastStep
+	if v := ctx.Query("step"); v != "" {
+		var err error
+		step, err = time.ParseDuration(v)
+		if err != nil || step < time.Minute {
+			ctx.JSON(http.StatusBadRequest, errorResponse{Message: "step: must be a duration of at least 1m"})
+			return
+		}
+	}
+	window := time.Duration(hours) * time.Hour
+	if window/step >= scaling.MaxForecastPoints {
+		minStep := (window/(scaling.MaxForecastPoints-1) + time.Minute - 1).Truncate(time.Minute)
+		ctx.JSON(http.StatusBadRequest, errorResponse{Message: fmt.Sprintf(
+			"step: %d hours in %s steps is over %d points, use a step of at least %s", hours, step, scaling.MaxForecastPoints, minStep)})
+		return
+	}
+
+	from := c.clock.Now().Truncate(step)
+	fr := forecastResponse{Mode: c.opSpec.Mode, From: from, To: from.Add(window), Step: step.String()}
+	points, err := scaling.Forecast(ctx.Request.Context(), c.spec(), fr.From, fr.To, step, c.clock)
+	switch {
+	case errors.Is(err, scaling.ErrNoForecast):
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse{Message: err.Error()})
+		return
+	case err != nil:
+		// The query was checked above, so what's left is the mode or the
+		// provider it reads from failing.
+		ctx.JSON(http.StatusBadGateway, errorResponse{Message: err.Error()})
+		return
+	}
+	fr.Points = points
+	fr.Changes = scaling.Changes(points)
+	ctx.JSON(http.StatusOK, fr)
+}
+
+// upcoming is the docket's preview of the changes forecast over the next day.
+func (c *CaptainController) upcoming(ctx context.Context) []scaling.ForecastPoint {
+	c.previewMu.Lock()
+	defer c.previewMu.Unlock()
+	from := c.clock.Now().Truncate(time.Minute)
+	if from.Equal(c.previewAt) {
+		return c.preview
+	}
+
+	points, err := scaling.Forecast(ctx, c.spec(), from, from.Add(defaultForecastHours*time.Hour), time.Minute, c.clock)
+	if err != nil && !errors.Is(err, scaling.ErrNoForecast) {
+		log.Warn().Err(err).Msg("error forecasting the docket")
+	}
+	changes := scaling.Changes(points)
+	if len(changes) > 0 {
+		changes = changes[1:]
+	}
+	c.previewAt = from
+	c.preview = changes[:min(len(changes), docketForecastChanges)]
+	return c.preview
+}
+
 func (c *CaptainController) docketHtml(ctx *gin.Context) {
 	dr := docketResponse{
 		Spec:       c.opSpec,
@@ -169,20 +369,36 @@ func (c *CaptainController) docketHtml(ctx *gin.Context) {
 	for k, v := range c.conscripts {
 		dr.Conscripts[k] = v.LastSeen
 	}
-	if c.opSpec.Mode == "trig" {
-		args := trig.Args{
-			Min:      c.opSpec.Trig.Min,
-			Max:      c.opSpec.Trig.Max,
-			Duration: c.opSpec.Trig.Duration,
+	dr.Forecast = c.upcoming(ctx.Request.Context())
+	target, err := c.target()
+	if err != nil {
+		log.Warn().Err(err).Msg("error calculating the target conscripts")
+	}
+	dr.Target = target
+	if c.opSpec.Mode == "trig" && c.waveErr == nil {
+		svg := &bytes.Buffer{}
+		if err := trig.Render(svg, trig.FormatSVG, c.wave, c.clock.Now(), trig.ChartOptions{}); err == nil {
+			dr.Chart = template.HTML(svg.String())
+		}
+	}
+	if c.opSpec.Mode == "schedule" {
+		s, err := schedule.New(c.opSpec.Schedule)
+		if err == nil {
+			now := c.clock.Now()
+			if active, ok := s.Active(now); ok {
+				dr.Active = &active
+			}
+			dr.Schedule = s.Upcoming(now, upcomingScheduleChanges)
+		}
+	}
+	if c.opSpec.Mode == "composite" {
+		if e, ok := c.evaluation(); ok {
+			dr.Contributions = e.Contributions
 		}
-		target, _ := trig.GetValue(args)
-		dr.Target = int(target)
-		dr.Trig = trig.RenderChart(args)
 	}
 
 	buf := bytes.NewBufferString("")
-	err := c.docketTmpl.Execute(buf, dr)
-	if err != nil {
+	if err := c.docketTmpl.Execute(buf, dr); err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse{Message: fmt.Errorf("error rendering page: %w", err).Error()})
 		return
 	}
@@ -191,6 +407,52 @@ func (c *CaptainController) docketHtml(ctx *gin.Context) {
 	ctx.Writer.Write(buf.Bytes())
 }
 
+// spec is the operator spec with the replay series read from the mounted
+// ConfigMaps, which the kubelet keeps in sync with their edits.
+func (c *CaptainController) spec() shared.OperatorSpec {
+	spec := c.opSpec
+	if spec.Replay.SeriesConfigMapRef != nil {
+		spec.Replay.Series = c.series(shared.ReplaySeriesFile)
+	}
+	spec.Composite.Children = slices.Clone(spec.Composite.Children)
+	for i := range spec.Composite.Children {
+		if spec.Composite.Children[i].Replay.SeriesConfigMapRef != nil {
+			spec.Composite.Children[i].Replay.Series = c.series(shared.ChildReplaySeriesFile(i))
+		}
+	}
+	return spec
+}
+
+// series reads a mounted replay series. A missing series is left empty and
+// forecasts as such.
+func (c *CaptainController) series(name string) string {
+	data, err := os.ReadFile(filepath.Join(c.seriesDir, name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warn().Err(err).Str("series", name).Msg("error reading replay series")
+	}
+	return string(data)
+}
+
+// evaluation reads the operator's last evaluation of the Ship from the
+// mounted ConfigMap. It is missing until the operator has evaluated the mode
+// once, and lags it by the kubelet's ConfigMap sync period.
+func (c *CaptainController) evaluation() (shared.Evaluation, bool) {
+	e := shared.Evaluation{}
+	data, err := os.ReadFile(c.evaluationPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Warn().Err(err).Msg("error reading the operator's evaluation")
+		}
+		return e, false
+	}
+	err = json.Unmarshal(data, &e)
+	if err != nil {
+		log.Warn().Err(err).Msg("error parsing the operator's evaluation")
+		return e, false
+	}
+	return e, true
+}
+
 func (c *CaptainController) routinePurger(ctx context.Context) chan bool {
 	stop := make(chan bool)
 
@@ -218,7 +480,7 @@ func (c *CaptainController) purgeConscripts(ctx context.Context) {
 		_, conSpan := tracer.Start(ctx, "conscript_check")
 		conSpan.SetAttributes(attribute.String("conscript_ip", v.IP))
 
-		if time.Since(v.LastSeen) > c.cycleStaleDuration {
+		if c.clock.Now().Sub(v.LastSeen) > c.cycleStaleDuration {
 			log.Debug().Msgf("Cycling stale conscripts %s", k)
 			conSpan.AddEvent("conscript_remove")
 			log.Debug().Msgf("purging %s", k)
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/clock"
+	"github.com/socialviolation/freyr/shared/trig"
+)
+
+func TestPurgeConscriptsOnceStale(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	c := &CaptainController{
+		cycleStaleDuration: 3 * time.Second,
+		conscripts:         map[string]Conscript{},
+		clock:              clk,
+	}
+	c.conscripts["10.0.0.1:5000"] = Conscript{IP: "10.0.0.1:5000", LastSeen: clk.Now()}
+	clk.Advance(2 * time.Second)
+	c.conscripts["10.0.0.2:5000"] = Conscript{IP: "10.0.0.2:5000", LastSeen: clk.Now()}
+
+	clk.Advance(2 * time.Second)
+	c.purgeConscripts(context.Background())
+	if _, ok := c.conscripts["10.0.0.1:5000"]; ok {
+		t.Error("a conscript unseen for 4s was not purged")
+	}
+	if _, ok := c.conscripts["10.0.0.2:5000"]; !ok {
+		t.Error("a conscript seen 2s ago was purged")
+	}
+}
+
+func TestEvaluationReadsTheOperatorsConfigMap(t *testing.T) {
+	c := &CaptainController{evaluationPath: filepath.Join(t.TempDir(), shared.EvaluationFile)}
+	if _, ok := c.evaluation(); ok {
+		t.Fatal("an evaluation was read before the operator published one")
+	}
+
+	data := `{"target":4,"contributions":[{"name":"weather","mode":"weather","target":4},{"name":"floor","mode":"manual","target":2}]}`
+	if err := os.WriteFile(c.evaluationPath, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, ok := c.evaluation()
+	if !ok {
+		t.Fatal("the published evaluation was not read")
+	}
+	if e.Target != 4 || len(e.Contributions) != 2 || e.Contributions[1] != (shared.Contribution{Name: "floor", Mode: "manual", Target: 2}) {
+		t.Errorf("evaluation = %+v", e)
+	}
+}
+
+func TestSpecReadsTheMountedReplaySeries(t *testing.T) {
+	ref := &shared.ConfigMapKeyRef{Name: "traffic", Key: "monday.csv"}
+	c := &CaptainController{
+		seriesDir: t.TempDir(),
+		opSpec: shared.OperatorSpec{
+			Mode:   "replay",
+			Replay: shared.ReplayMode{SeriesConfigMapRef: ref},
+			Composite: shared.CompositeMode{Children: []shared.CompositeChild{
+				{Mode: "manual"},
+				{Mode: "replay", Replay: shared.ReplayMode{SeriesConfigMapRef: ref}},
+			}},
+		},
+	}
+	for name, series := range map[string]string{
+		shared.ReplaySeriesFile:         "0,2\n60s,6\n",
+		shared.ChildReplaySeriesFile(1): "0,1\n60s,3\n",
+	} {
+		if err := os.WriteFile(filepath.Join(c.seriesDir, name), []byte(series), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spec := c.spec()
+	if spec.Replay.Series != "0,2\n60s,6\n" {
+		t.Errorf("replay series = %q", spec.Replay.Series)
+	}
+	if got := spec.Composite.Children[1].Replay.Series; got != "0,1\n60s,3\n" {
+		t.Errorf("child replay series = %q", got)
+	}
+	if c.opSpec.Composite.Children[1].Replay.Series != "" {
+		t.Error("reading the series modified the operator spec")
+	}
+}
+
+func TestForecastStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clk := clock.NewFake(time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC))
+	manual := shared.OperatorSpec{Mode: "manual", Replicas: new(int32)}
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	tests := []struct {
+		name    string
+		spec    shared.OperatorSpec
+		query   string
+		want    int
+		message string
+	}{
+		{"forecasts", manual, "hours=168&step=2m", http.StatusOK, ""},
+		{"too many points", manual, "hours=168&step=1m", http.StatusBadRequest, "at least 2m0s"},
+		{"mode can't forecast", shared.OperatorSpec{Mode: "prometheus"}, "", http.StatusUnprocessableEntity, ""},
+		{"redacted openweather key", shared.OperatorSpec{Mode: "weather", Weather: shared.WeatherMode{
+			Provider: "openweather", City: "Melbourne", Country: "AU",
+		}}, "", http.StatusUnprocessableEntity, "API key"},
+		{"provider fails", shared.OperatorSpec{Mode: "weather", Weather: shared.WeatherMode{
+			Provider: "fake", URL: down.URL,
+		}}, "", http.StatusBadGateway, "HTTP 503"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CaptainController{opSpec: tt.spec, clock: clk}
+			r := gin.New()
+			r.GET("/forecast", c.forecast)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/forecast?"+tt.query, nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.message) {
+				t.Errorf("body = %s, want it to mention %q", w.Body, tt.message)
+			}
+		})
+	}
+}
+
+func TestDocketTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	evaluationPath := filepath.Join(t.TempDir(), shared.EvaluationFile)
+	if err := os.WriteFile(evaluationPath, []byte(`{"target":7}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		spec shared.OperatorSpec
+		want int
+		body string
+	}{
+		{"reports the operator's target", shared.OperatorSpec{Mode: "replay"}, http.StatusOK, `"target":7`},
+		{"fails once on a broken wave", shared.OperatorSpec{Mode: "trig"}, http.StatusInternalServerError, "error calculating the target conscripts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CaptainController{
+				opSpec:         tt.spec,
+				clock:          clock.Real{},
+				conscripts:     map[string]Conscript{},
+				evaluationPath: evaluationPath,
+			}
+			args, err := trig.FromSpec(tt.spec.Trig)
+			if err == nil {
+				c.wave, err = trig.NewWave(args)
+			}
+			c.waveErr = err
+
+			r := gin.New()
+			r.GET("/conscripts", c.docket)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/conscripts", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			// A handler writing twice concatenates two JSON documents.
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Errorf("body = %s, want a single JSON response: %v", w.Body, err)
+			}
+			if !strings.Contains(w.Body.String(), tt.body) {
+				t.Errorf("body = %s, want it to contain %s", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestDocketHtmlTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	evaluationPath := filepath.Join(t.TempDir(), shared.EvaluationFile)
+	if err := os.WriteFile(evaluationPath, []byte(`{"target":7}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []string{"replay", "weather", "manual"} {
+		t.Run(mode, func(t *testing.T) {
+			t.Setenv("OPERATOR_CONFIG", fmt.Sprintf(`{"mode":%q}`, mode))
+			c, err := NewCaptainController()
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.evaluationPath = evaluationPath
+
+			r := gin.New()
+			r.GET("/", c.docketHtml)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if want := "<strong>Target: </strong> 7<"; !strings.Contains(w.Body.String(), want) {
+				t.Errorf("body = %s, want it to contain %s", w.Body, want)
+			}
+		})
+	}
+}
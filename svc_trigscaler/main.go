@@ -0,0 +1,132 @@
+// Command trigscaler is a reference external scaler. It serves the
+// externalscaler gRPC protocol and answers with the trig wave described by the
+// Ship's external.spec, so trig mode can run out of process:
+//
+//	mode: external
+//	external:
+//	  address: trigscaler.freyr:9090
+//	  spec:
+//	    duration: 300s
+//	    min: "1"
+//	    max: "6"
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/socialviolation/freyr/shared"
+	"github.com/socialviolation/freyr/shared/externalscaler"
+	"github.com/socialviolation/freyr/shared/trig"
+)
+
+type trigScaler struct {
+	externalscaler.UnimplementedExternalScalerServer
+}
+
+func (trigScaler) GetTarget(_ context.Context, req *externalscaler.GetTargetRequest) (*externalscaler.GetTargetResponse, error) {
+	spec := req.GetSpec()
+	mode := shared.TrigMode{
+		Duration:  spec["duration"],
+		Waveform:  spec["waveform"],
+		Phase:     spec["phase"],
+		Amplitude: spec["amplitude"],
+		Anchor:    spec["anchor"],
+		Timezone:  spec["timezone"],
+	}
+	for key, bound := range map[string]*int32{"min": &mode.Min, "max": &mode.Max} {
+		v, err := strconv.ParseInt(spec[key], 10, 32)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "spec.%s: %v", key, err)
+		}
+		*bound = int32(v)
+	}
+	if mode.Min > mode.Max {
+		return nil, status.Errorf(codes.InvalidArgument, "spec.min (%d) must not be greater than spec.max (%d)", mode.Min, mode.Max)
+	}
+	if v, ok := spec["dutyCycle"]; ok {
+		duty, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "spec.dutyCycle: %v", err)
+		}
+		mode.DutyCycle = int32(duty)
+	}
+	if v, ok := spec["seed"]; ok {
+		seed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "spec.seed: %v", err)
+		}
+		mode.Seed = seed
+	}
+	args, err := trig.FromSpec(mode)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	at := time.Now()
+	if req.GetTime() != nil {
+		at = req.GetTime().AsTime()
+	}
+	target, err := trig.GetValue(args, at)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "spec.duration: %v", err)
+	}
+	log.Info().Msgf("%s/%s target %d", req.GetShip().GetNamespace(), req.GetShip().GetName(), int32(target))
+	return &externalscaler.GetTargetResponse{Replicas: int32(target)}, nil
+}
+
+func main() {
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	viper.SetEnvPrefix("")
+	viper.SetDefault("host.name", "0.0.0.0")
+	viper.SetDefault("host.port", 9090)
+	viper.SetDefault("tls.cert", "")
+	viper.SetDefault("tls.key", "")
+
+	var opts []grpc.ServerOption
+	if cert := viper.GetString("tls.cert"); cert != "" {
+		creds, err := credentials.NewServerTLSFromFile(cert, viper.GetString("tls.key"))
+		if err != nil {
+			log.Error().Err(err).Msg("error loading tls certificate")
+			os.Exit(1)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	addr := fmt.Sprintf("%s:%d", viper.GetString("host.name"), viper.GetInt32("host.port"))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error().Err(err).Msgf("error listening on %s", addr)
+		os.Exit(1)
+	}
+	srv := grpc.NewServer(opts...)
+	externalscaler.RegisterExternalScalerServer(srv, trigScaler{})
+
+	go func() {
+		log.Info().Msgf("serving @ %s", addr)
+		if err := srv.Serve(lis); err != nil {
+			log.Error().Err(err).Msg("error during serve")
+		}
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+
+	<-c
+	srv.GracefulStop()
+	log.Info().Msg("gracefully shut down")
+}